@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pmatseykanets/gh-tools/duration"
+)
+
+type agePredicate struct {
+	op    int           // <0 - less than, >0 greater than
+	value time.Duration // Age
+}
+
+func (p *agePredicate) match(value time.Duration) bool {
+	if p.op > 0 {
+		return value >= p.value
+	}
+
+	return value <= p.value
+}
+
+// parseAgePredicate parses the age predicate in the form [+-]<duration>.
+func parseAgePredicate(s string) (*agePredicate, error) {
+	p := &agePredicate{}
+	switch {
+	case strings.HasPrefix(s, "+"):
+		p.op = 1
+	case strings.HasPrefix(s, "-"):
+		p.op = -1
+	default:
+		return nil, fmt.Errorf("invalid age %s: should start with + or -", s)
+	}
+
+	value, err := duration.Parse(s[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid age %s: %s", s, err)
+	}
+	p.value = value
+
+	return p, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAgePredicate(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		in  string
+		op  int
+		age time.Duration
+		err bool
+	}{
+		{"+1y", 1, 365 * day, false},
+		{"-30d", -1, 30 * day, false},
+		{"+2w", 1, 14 * day, false},
+		{"-12h", -1, 12 * time.Hour, false},
+		{"1y", 0, 0, true},
+		{"+", 0, 0, true},
+		{"+xd", 0, 0, true},
+		{"+1x", 0, 0, true},
+		{"+-1h", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := parseAgePredicate(tt.in)
+			if tt.err {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tt.op, p.op; want != got {
+				t.Errorf("Expected op %d got %d", want, got)
+			}
+			if want, got := tt.age, p.value; want != got {
+				t.Errorf("Expected age %s got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestAgePredicateMatch(t *testing.T) {
+	older := &agePredicate{op: 1, value: time.Hour}
+	if !older.match(2*time.Hour) || older.match(time.Minute) {
+		t.Errorf("Expected +1h to match only older entries")
+	}
+	newer := &agePredicate{op: -1, value: time.Hour}
+	if !newer.match(time.Minute) || newer.match(2*time.Hour) {
+		t.Errorf("Expected -1h to match only newer entries")
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// openContents opens the contents of the entry. Once the number of files
+// to grep in the repository exceeds the tarball threshold the repository
+// tarball is downloaded once and the rest of the files are read locally
+// instead of downloading them one by one. Entries missing from the tarball
+// (e.g. symlinks or export-ignore'd paths) are still downloaded.
+func (f *finder) openContents(ctx context.Context, repo *github.Repository, branch string, entry *github.TreeEntry) (io.ReadCloser, error) {
+	f.downloads++
+	if f.archiveDir == "" && !f.noArchive &&
+		f.config.tarballThreshold >= 0 && f.downloads > f.config.tarballThreshold {
+		dir, err := f.downloadArchive(ctx, repo, branch)
+		if err != nil {
+			// Fall back to downloading files one by one.
+			fmt.Fprintf(f.stderr, "%s: error downloading tarball: %s\n", repo.GetFullName(), err)
+			f.noArchive = true
+		}
+		f.archiveDir = dir
+	}
+
+	if f.archiveDir != "" {
+		file, err := os.Open(filepath.Join(f.archiveDir, filepath.FromSlash(entry.GetPath())))
+		if !os.IsNotExist(err) {
+			return file, err
+		}
+	}
+
+	opts := &github.RepositoryContentGetOptions{Ref: branch}
+	return f.gh.Repositories.DownloadContents(ctx, repo.GetOwner().GetLogin(), repo.GetName(), entry.GetPath(), opts)
+}
+
+// closeArchive removes the extracted repository tarball if any
+// and resets the per repository state.
+func (f *finder) closeArchive() {
+	if f.archiveDir != "" {
+		os.RemoveAll(f.archiveDir)
+	}
+	f.archiveDir = ""
+	f.noArchive = false
+	f.downloads = 0
+	f.timedOut = false
+	if f.cancelRepo != nil {
+		f.cancelRepo()
+		f.cancelRepo = nil
+	}
+}
+
+// downloadArchive downloads and extracts the repository tarball to a temp directory.
+func (f *finder) downloadArchive(ctx context.Context, repo *github.Repository, branch string) (string, error) {
+	opts := &github.RepositoryContentGetOptions{Ref: branch}
+	link, _, err := f.gh.Repositories.GetArchiveLink(ctx, repo.GetOwner().GetLogin(), repo.GetName(), github.Tarball, opts, false)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %s", resp.Status)
+	}
+
+	dir, err := ioutil.TempDir("", "gh-find")
+	if err != nil {
+		return "", err
+	}
+
+	if err = extractTarball(f.summary.countBytes(resp.Body), dir); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// extractTarball extracts regular files from the gzipped tarball to dir
+// stripping the top level directory GitHub puts all files in.
+func extractTarball(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// Strip the top level directory (owner-repo-sha/).
+		name := path.Clean(header.Name)
+		i := strings.Index(name, "/")
+		if i < 0 {
+			continue
+		}
+		name = name[i+1:]
+		if name == "" || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path in tarball: %s", header.Name)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(file, reader)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+)
+
+func makeTarball(t *testing.T, files map[string]string) *bytes.Buffer {
+	t.Helper()
+
+	return makeTarballWithLinks(t, files, nil)
+}
+
+func makeTarballWithLinks(t *testing.T, files, links map[string]string) *bytes.Buffer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, target := range links {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0777, Linkname: target, Typeflag: tar.TypeSymlink})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, contents := range files {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(contents)), Typeflag: tar.TypeReg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = tw.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf
+}
+
+func TestExtractTarball(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gh-find")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tarball := makeTarball(t, map[string]string{
+		"foo-bar-abc123/go.mod":        "module foo",
+		"foo-bar-abc123/cmd/main.go":   "package main",
+		"foo-bar-abc123/docs/README":   "docs",
+		"pax_global_header_equivalent": "ignored",
+	})
+	if err = extractTarball(tarball, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		"go.mod":      "module foo",
+		"cmd/main.go": "package main",
+		"docs/README": "docs",
+	} {
+		got, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want != string(got) {
+			t.Errorf("Expected %q got %q", want, got)
+		}
+	}
+}
+
+func TestExtractTarballTraversal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gh-find")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tarball := makeTarball(t, map[string]string{"foo-bar-abc123/../../../evil": "evil"})
+	if err = extractTarball(tarball, dir); err == nil {
+		t.Errorf("Expected an error")
+	}
+}
+
+func TestOpenContentsMissingFromTarball(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gh-find")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tarball := makeTarballWithLinks(t,
+		map[string]string{"foo-bar-abc123/docs/README": "docs"},
+		map[string]string{"foo-bar-abc123/docs/LINK": "README"},
+	)
+	if err = extractTarball(tarball, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/repos/foo/bar/contents/docs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"name":"LINK","path":"docs/LINK","download_url":"%s/raw/docs/LINK"}]`, server.URL)
+	})
+	mux.HandleFunc("/raw/docs/LINK", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "README")
+	})
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	f := &finder{gh: client, archiveDir: dir}
+	repo := &github.Repository{Name: github.String("bar"), Owner: &github.User{Login: github.String("foo")}}
+
+	for path, want := range map[string]string{
+		"docs/README": "docs",   // Read from the tarball.
+		"docs/LINK":   "README", // Downloaded.
+	} {
+		contents, err := f.openContents(context.Background(), repo, "main", &github.TreeEntry{Path: github.String(path)})
+		if err != nil {
+			t.Fatalf("%s: %s", path, err)
+		}
+		got, err := ioutil.ReadAll(contents)
+		contents.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want != string(got) {
+			t.Errorf("%s: Expected %q got %q", path, want, got)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// treeCache caches repository tree listings on disk.
+// Trees are keyed by the commit SHA and therefore never go stale.
+type treeCache struct {
+	dir string
+}
+
+// newTreeCache creates a new treeCache instance in the user cache directory.
+func newTreeCache() (*treeCache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &treeCache{dir: filepath.Join(dir, "gh-tools", "trees")}, nil
+}
+
+func (c *treeCache) path(repo, sha string) string {
+	return filepath.Join(c.dir, filepath.FromSlash(repo), sha+".json")
+}
+
+// get returns cached entries of the repository tree at the commit.
+func (c *treeCache) get(repo, sha string) ([]*github.TreeEntry, bool) {
+	contents, err := ioutil.ReadFile(c.path(repo, sha))
+	if err != nil {
+		return nil, false
+	}
+
+	var entries []*github.TreeEntry
+	if err = json.Unmarshal(contents, &entries); err != nil {
+		return nil, false
+	}
+
+	return entries, true
+}
+
+// put caches entries of the repository tree at the commit.
+func (c *treeCache) put(repo, sha string, entries []*github.TreeEntry) error {
+	contents, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	path := c.path(repo, sha)
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// Write to a temp file first so that an interrupted run doesn't leave a partial cache entry.
+	file, err := ioutil.TempFile(filepath.Dir(path), ".tree")
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(contents); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err = file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+)
+
+func TestTreeCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gh-find")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache := &treeCache{dir: dir}
+	if _, ok := cache.get("foo/bar", "abc"); ok {
+		t.Fatal("Expected a cache miss")
+	}
+
+	entries := []*github.TreeEntry{
+		{Path: github.String("go.mod"), Type: github.String("blob"), Mode: github.String("100644"), Size: github.Int(10), SHA: github.String("def")},
+		{Path: github.String("cmd"), Type: github.String("tree"), Mode: github.String("040000"), SHA: github.String("ghi")},
+	}
+	if err = cache.put("foo/bar", "abc", entries); err != nil {
+		t.Fatal(err)
+	}
+
+	cached, ok := cache.get("foo/bar", "abc")
+	if !ok {
+		t.Fatal("Expected a cache hit")
+	}
+	if want, got := entries, cached; !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected %v got %v", want, got)
+	}
+
+	if _, ok := cache.get("foo/bar", "xyz"); ok {
+		t.Fatal("Expected a cache miss")
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// checkpoint records the progress of a run so that it can be resumed.
+// All methods are no-op on a nil checkpoint.
+type checkpoint struct {
+	path  string
+	Repos []checkpointRepo `json:"repos"` // Completed repositories.
+}
+
+// checkpointRepo represents a completed repository.
+type checkpointRepo struct {
+	Repo    string `json:"repo"` // The full repository name owner/repo.
+	Matches int    `json:"matches"`
+}
+
+// readCheckpoint reads the checkpoint file. A missing file yields an empty checkpoint.
+func readCheckpoint(path string) (*checkpoint, error) {
+	cp := &checkpoint{path: path}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cp, nil
+		}
+		return nil, fmt.Errorf("can't read checkpoint file %s: %s", path, err)
+	}
+
+	if err = json.Unmarshal(contents, cp); err != nil {
+		return nil, fmt.Errorf("can't parse checkpoint file %s: %s", path, err)
+	}
+
+	return cp, nil
+}
+
+// completed checks if the repository has been completed.
+func (c *checkpoint) completed(repo string) bool {
+	if c == nil {
+		return false
+	}
+
+	for _, r := range c.Repos {
+		if r.Repo == repo {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matches returns the number of matched entries in completed repositories.
+func (c *checkpoint) matches() int {
+	if c == nil {
+		return 0
+	}
+
+	var n int
+	for _, r := range c.Repos {
+		n += r.Matches
+	}
+
+	return n
+}
+
+// complete records the repository as completed and writes the checkpoint file.
+func (c *checkpoint) complete(repo string, matches int) error {
+	if c == nil {
+		return nil
+	}
+
+	c.Repos = append(c.Repos, checkpointRepo{Repo: repo, Matches: matches})
+	return c.write()
+}
+
+// write writes the checkpoint file. It writes to a temp file first
+// and then renames it so that an interrupted run doesn't corrupt the checkpoint.
+func (c *checkpoint) write() error {
+	contents, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(c.path), ".gh-find-checkpoint")
+	if err != nil {
+		return fmt.Errorf("can't write checkpoint file %s: %s", c.path, err)
+	}
+	defer os.Remove(file.Name()) // Clean up if renaming fails.
+
+	_, err = file.Write(contents)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("can't write checkpoint file %s: %s", c.path, err)
+	}
+
+	if err = os.Rename(file.Name(), c.path); err != nil {
+		return fmt.Errorf("can't write checkpoint file %s: %s", c.path, err)
+	}
+
+	return nil
+}
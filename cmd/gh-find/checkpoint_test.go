@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gh-find")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "checkpoint.json")
+
+	cp, err := readCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.completed("foo/bar") {
+		t.Error("Expected foo/bar not to be completed")
+	}
+	if err = cp.complete("foo/bar", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err = cp.complete("foo/baz", 3); err != nil {
+		t.Fatal(err)
+	}
+
+	cp, err = readCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cp.completed("foo/bar") || !cp.completed("foo/baz") {
+		t.Errorf("Expected foo/bar and foo/baz to be completed got %v", cp.Repos)
+	}
+	if cp.completed("foo/qux") {
+		t.Error("Expected foo/qux not to be completed")
+	}
+	if want, got := 5, cp.matches(); want != got {
+		t.Errorf("Expected %d matches got %d", want, got)
+	}
+
+	var nilCheckpoint *checkpoint
+	if err = nilCheckpoint.complete("foo/bar", 1); err != nil {
+		t.Fatal(err)
+	}
+	if nilCheckpoint.completed("foo/bar") {
+		t.Error("Expected foo/bar not to be completed")
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pmatseykanets/gh-tools/terminal"
+)
+
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// ANSI escape sequences.
+const (
+	colorRepo  = "\x1b[35m"   // Magenta.
+	colorMatch = "\x1b[1;31m" // Bold red.
+	colorReset = "\x1b[0m"
+)
+
+// useColor decides whether to colorize the output.
+// In the auto mode the output is colorized only if stdout is a terminal
+// and the NO_COLOR environment variable is not set.
+func useColor(mode string) bool {
+	switch mode {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	default:
+		if _, ok := os.LookupEnv("NO_COLOR"); ok {
+			return false
+		}
+		return terminal.IsTerminal(os.Stdout)
+	}
+}
+
+// colorize returns a copy of the result with the repository name
+// and the matched portions of the pathname and the grep line highlighted.
+func (f *finder) colorize(r *result) *result {
+	colored := *r
+	colored.Repo = colorRepo + r.Repo + colorReset
+
+	if r.Path != "" {
+		ranges := matchRanges(r.Path, f.config.pathRegexp, 0)
+		dir, basename := path.Split(r.Path)
+		ranges = append(ranges, matchRanges(basename, f.config.nameRegexp, len(dir))...)
+		colored.Path = highlight(r.Path, ranges)
+	}
+
+	if r.Line != "" && f.config.grepRegexp != nil {
+		colored.Line = highlight(r.Line, matchRanges(r.Line, []*regexp.Regexp{f.config.grepRegexp}, 0))
+	}
+
+	return &colored
+}
+
+// matchRanges returns byte ranges of all matches of the patterns in s shifted by the offset.
+func matchRanges(s string, regexes []*regexp.Regexp, offset int) [][]int {
+	var ranges [][]int
+	for _, regex := range regexes {
+		for _, loc := range regex.FindAllStringIndex(s, -1) {
+			if loc[0] == loc[1] {
+				continue // Skip empty matches.
+			}
+			ranges = append(ranges, []int{loc[0] + offset, loc[1] + offset})
+		}
+	}
+
+	return ranges
+}
+
+// highlight wraps byte ranges of s, merging overlapping ones, with the match color.
+func highlight(s string, ranges [][]int) string {
+	if len(ranges) == 0 {
+		return s
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+
+	var (
+		b    strings.Builder
+		last int
+	)
+	for i := 0; i < len(ranges); i++ {
+		start, end := ranges[i][0], ranges[i][1]
+		for i+1 < len(ranges) && ranges[i+1][0] <= end {
+			i++
+			if ranges[i][1] > end {
+				end = ranges[i][1]
+			}
+		}
+		if start < last {
+			start = last
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(colorMatch)
+		b.WriteString(s[start:end])
+		b.WriteString(colorReset)
+		last = end
+	}
+	b.WriteString(s[last:])
+
+	return b.String()
+}
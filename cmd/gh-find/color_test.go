@@ -0,0 +1,51 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		desc   string
+		s      string
+		ranges [][]int
+		out    string
+	}{
+		{"no ranges", "foo", nil, "foo"},
+		{"single", "foobar", [][]int{{3, 6}}, "foo" + colorMatch + "bar" + colorReset},
+		{"multiple", "foobarfoo", [][]int{{6, 9}, {0, 3}}, colorMatch + "foo" + colorReset + "bar" + colorMatch + "foo" + colorReset},
+		{"overlapping", "foobar", [][]int{{0, 4}, {2, 6}}, colorMatch + "foobar" + colorReset},
+		{"nested", "foobar", [][]int{{0, 6}, {2, 3}}, colorMatch + "foobar" + colorReset},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if want, got := tt.out, highlight(tt.s, tt.ranges); want != got {
+				t.Errorf("Expected %q got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestColorize(t *testing.T) {
+	f := &finder{config: config{
+		nameRegexp: []*regexp.Regexp{regexp.MustCompile("^go")},
+		pathRegexp: []*regexp.Regexp{regexp.MustCompile("^cmd/")},
+		grepRegexp: regexp.MustCompile("sync"),
+	}}
+
+	r := f.colorize(&result{Repo: "foo/bar", Path: "cmd/go.mod", LineNo: 1, Line: "golang.org/x/sync v0.1.0"})
+	if want, got := colorRepo+"foo/bar"+colorReset, r.Repo; want != got {
+		t.Errorf("Expected %q got %q", want, got)
+	}
+	if want, got := colorMatch+"cmd/go"+colorReset+".mod", r.Path; want != got {
+		t.Errorf("Expected %q got %q", want, got)
+	}
+	if want, got := "golang.org/x/"+colorMatch+"sync"+colorReset+" v0.1.0", r.Line; want != got {
+		t.Errorf("Expected %q got %q", want, got)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import "sort"
+
+// duplicateGroups groups results by the object SHA and returns groups
+// of identical entries appearing in more than one repository or path.
+// Only the first result per entry is kept (e.g. out of many grep matches).
+// Groups are ordered by the number of copies, the largest first.
+func duplicateGroups(results []*result) [][]*result {
+	type key struct{ repo, path string }
+
+	var (
+		shas   []string
+		groups = map[string][]*result{}
+		seen   = map[key]bool{}
+	)
+	for _, r := range results {
+		if r.SHA == "" || seen[key{r.Repo, r.Path}] {
+			continue
+		}
+		seen[key{r.Repo, r.Path}] = true
+
+		if _, ok := groups[r.SHA]; !ok {
+			shas = append(shas, r.SHA)
+		}
+		dup := *r
+		dup.LineNo, dup.Line = 0, ""
+		groups[r.SHA] = append(groups[r.SHA], &dup)
+	}
+
+	var dups [][]*result
+	for _, sha := range shas {
+		if len(groups[sha]) > 1 {
+			dups = append(dups, groups[sha])
+		}
+	}
+	sort.SliceStable(dups, func(i, j int) bool {
+		return len(dups[i]) > len(dups[j])
+	})
+
+	return dups
+}
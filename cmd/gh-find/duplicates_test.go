@@ -0,0 +1,38 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDuplicateGroups(t *testing.T) {
+	results := []*result{
+		{Repo: "foo/a", Path: "Makefile", SHA: "1"},
+		{Repo: "foo/a", Path: "build.sh", SHA: "2", LineNo: 1, Line: "set -e"},
+		{Repo: "foo/a", Path: "build.sh", SHA: "2", LineNo: 5, Line: "go build"},
+		{Repo: "foo/b", Path: "scripts/build.sh", SHA: "2", LineNo: 3, Line: "set -e"},
+		{Repo: "foo/b", Path: "README.md", SHA: "3"},
+		{Repo: "foo/c", Path: "Makefile", SHA: "1"},
+		{Repo: "foo/c", Path: "ci/build.sh", SHA: "2"},
+		{Repo: "foo/d"}, // No matches.
+	}
+
+	want := [][]*result{
+		{
+			{Repo: "foo/a", Path: "build.sh", SHA: "2"},
+			{Repo: "foo/b", Path: "scripts/build.sh", SHA: "2"},
+			{Repo: "foo/c", Path: "ci/build.sh", SHA: "2"},
+		},
+		{
+			{Repo: "foo/a", Path: "Makefile", SHA: "1"},
+			{Repo: "foo/c", Path: "Makefile", SHA: "1"},
+		},
+	}
+	if got := duplicateGroups(results); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected %v got %v", want, got)
+	}
+
+	if got := duplicateGroups(results[:2]); got != nil {
+		t.Errorf("Expected no groups got %v", got)
+	}
+}
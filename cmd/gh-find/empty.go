@@ -0,0 +1,46 @@
+package main
+
+import (
+	"path"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// placeholderNames are names of files conventionally used to keep otherwise empty directories in git.
+var placeholderNames = map[string]bool{
+	".gitkeep": true,
+	".keep":    true,
+}
+
+// nonEmptyDirs returns paths of directories that contain at least one entry
+// other than empty files and placeholder files at any depth.
+func nonEmptyDirs(entries []*github.TreeEntry) map[string]bool {
+	dirs := map[string]bool{}
+	for _, entry := range entries {
+		if entry.GetType() == "tree" {
+			continue
+		}
+		if entry.GetType() == "blob" && (entry.GetSize() == 0 || placeholderNames[path.Base(entry.GetPath())]) {
+			continue
+		}
+
+		for dir := path.Dir(entry.GetPath()); dir != "." && !dirs[dir]; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+
+	return dirs
+}
+
+// isEmpty checks if the entry is an empty file or a directory
+// that contains only empty files and placeholder files.
+func isEmpty(entry *github.TreeEntry, nonEmpty map[string]bool) bool {
+	switch entry.GetType() {
+	case "blob":
+		return entry.GetSize() == 0
+	case "tree":
+		return !nonEmpty[entry.GetPath()]
+	default:
+		return false
+	}
+}
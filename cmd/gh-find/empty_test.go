@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+)
+
+func TestIsEmpty(t *testing.T) {
+	blob := func(path string, size int) *github.TreeEntry {
+		return &github.TreeEntry{Path: github.String(path), Type: github.String("blob"), Size: github.Int(size)}
+	}
+	tree := func(path string) *github.TreeEntry {
+		return &github.TreeEntry{Path: github.String(path), Type: github.String("tree")}
+	}
+
+	entries := []*github.TreeEntry{
+		blob("README.md", 10),
+		blob("empty.txt", 0),
+		tree("a"),
+		tree("a/b"),
+		blob("a/b/.gitkeep", 0),
+		tree("a/c"),
+		blob("a/c/.keep", 5),
+		tree("d"),
+		tree("d/e"),
+		blob("d/e/main.go", 100),
+		blob("d/.gitkeep", 0),
+		tree("f"),
+		{Path: github.String("f/submodule"), Type: github.String("commit")},
+	}
+
+	nonEmpty := nonEmptyDirs(entries)
+	want := map[string]bool{
+		"README.md":    false,
+		"empty.txt":    true,
+		"a":            true,
+		"a/b":          true,
+		"a/b/.gitkeep": true,
+		"a/c":          true,
+		"a/c/.keep":    false,
+		"d":            false,
+		"d/e":          false,
+		"d/e/main.go":  false,
+		"d/.gitkeep":   true,
+		"f":            false,
+		"f/submodule":  false,
+	}
+	for _, entry := range entries {
+		if want, got := want[entry.GetPath()], isEmpty(entry, nonEmpty); want != got {
+			t.Errorf("%s: expected %v got %v", entry.GetPath(), want, got)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// Placeholders expanded in the arguments of the exec command.
+const (
+	execPathPlaceholder = "{}"     // The pathname of the entry.
+	execRepoPlaceholder = "{repo}" // The full repository name.
+	execFilePlaceholder = "{file}" // The path to the local copy of the file contents.
+	execTerminator      = ";"      // The optional terminator of the command.
+)
+
+// parseExec splits the exec command into arguments.
+// Arguments are split at spaces. Single and double quotes and backslashes
+// can be used to keep spaces within an argument as in the shell.
+func parseExec(s string) ([]string, error) {
+	var (
+		args    []string
+		arg     strings.Builder
+		inArg   bool // Inside an argument.
+		quoted  bool // The last argument contains quotes or escapes.
+		quote   rune // The open quote if any.
+		escaped bool // The previous character is a backslash.
+	)
+	for _, c := range s {
+		switch {
+		case escaped:
+			// Within double quotes a backslash escapes only special characters.
+			if quote == '"' && !strings.ContainsRune("$`\"\\\n", c) {
+				arg.WriteRune('\\')
+			}
+			arg.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped, inArg, quoted = true, true, true
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(c)
+		case c == '\'' || c == '"':
+			quote, inArg, quoted = c, true, true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			if !inArg {
+				quoted = false
+			}
+			arg.WriteRune(c)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("invalid exec command %s: unterminated quote or escape", s)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	if n := len(args); n > 0 && args[n-1] == execTerminator && !quoted {
+		args = args[:n-1]
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("exec command is empty")
+	}
+
+	return args, nil
+}
+
+// expandExec replaces placeholders in the exec command arguments.
+func expandExec(args []string, repo, path, file string) []string {
+	replacer := strings.NewReplacer(
+		execRepoPlaceholder, repo,
+		execFilePlaceholder, file,
+		execPathPlaceholder, path,
+	)
+
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		expanded[i] = replacer.Replace(arg)
+	}
+
+	return expanded
+}
+
+// needsFile checks if the exec command refers to the file contents.
+func needsFile(args []string) bool {
+	for _, arg := range args {
+		if strings.Contains(arg, execFilePlaceholder) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// exec runs the exec command for the matched entry.
+// A non-zero exit code is reported but doesn't stop the search.
+func (f *finder) exec(ctx context.Context, repo *github.Repository, branch string, entry *github.TreeEntry) error {
+	var file string
+	if needsFile(f.config.exec) && entry.GetType() == "blob" {
+		var err error
+		file, err = f.downloadFile(ctx, repo, branch, entry)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(file) // Clean up.
+	}
+
+	args := expandExec(f.config.exec, repo.GetFullName(), entry.GetPath(), file)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(),
+		"GH_FIND_REPO="+repo.GetFullName(),
+		"GH_FIND_PATH="+entry.GetPath(),
+		"GH_FIND_FILE="+file,
+	)
+	cmd.Stdout = f.stdout
+	cmd.Stderr = f.stderr
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return fmt.Errorf("%s: %s: exec error: %s", repo.GetFullName(), entry.GetPath(), err)
+		}
+		fmt.Fprintf(f.stderr, "%s: %s: exec error: %s\n", repo.GetFullName(), entry.GetPath(), err)
+	}
+
+	return nil
+}
+
+// downloadFile downloads the contents of the entry to a temp file.
+func (f *finder) downloadFile(ctx context.Context, repo *github.Repository, branch string, entry *github.TreeEntry) (string, error) {
+	opts := &github.RepositoryContentGetOptions{Ref: branch}
+	contents, err := f.gh.Repositories.DownloadContents(ctx, repo.GetOwner().GetLogin(), repo.GetName(), entry.GetPath(), opts)
+	if err != nil {
+		return "", err
+	}
+	defer contents.Close()
+
+	file, err := ioutil.TempFile("", "gh-find")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err = io.Copy(file, contents); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseExec(t *testing.T) {
+	tests := []struct {
+		in   string
+		args []string
+		err  bool
+	}{
+		{"cat {}", []string{"cat", "{}"}, false},
+		{"cat {} ;", []string{"cat", "{}"}, false},
+		{"  wc  -l  {file} ;", []string{"wc", "-l", "{file}"}, false},
+		{"", nil, true},
+		{";", nil, true},
+		{`grep -l 'foo bar' {file}`, []string{"grep", "-l", "foo bar", "{file}"}, false},
+		{`sh -c "echo \"{repo}\" {}" ;`, []string{"sh", "-c", `echo "{repo}" {}`}, false},
+		{`echo foo\ bar`, []string{"echo", "foo bar"}, false},
+		{`grep "^go 1\.1[0-6]$" {file}`, []string{"grep", `^go 1\.1[0-6]$`, "{file}"}, false},
+		{`grep '\.' {file}`, []string{"grep", `\.`, "{file}"}, false},
+		{`echo '' ';'`, []string{"echo", "", ";"}, false},
+		{`echo 'foo`, nil, true},
+		{`echo foo\`, nil, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			args, err := parseExec(tt.in)
+			if tt.err {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tt.args, args; !reflect.DeepEqual(want, got) {
+				t.Errorf("Expected %v got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestExpandExec(t *testing.T) {
+	args := []string{"echo", "{repo}", "{}", "--file={file}", "{repo}/{}"}
+	want := []string{"echo", "foo/bar", "a/b", "--file=/tmp/x", "foo/bar/a/b"}
+	if got := expandExec(args, "foo/bar", "a/b", "/tmp/x"); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected %v got %v", want, got)
+	}
+
+	if !needsFile(args) {
+		t.Errorf("Expected to need the file")
+	}
+	if needsFile([]string{"echo", "{}"}) {
+		t.Errorf("Expected not to need the file")
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// globRegexp converts a shell-style glob pattern to a regular expression
+// matching the pathname:
+//
+//   - `*` matches any sequence of characters except /
+//   - `?` matches any single character except /
+//   - `**` matches any sequence of characters including / (e.g. `**/*.tf`)
+//   - `[...]` matches a character class, `[!...]` negates it
+//
+// Patterns without a / match the last component of the pathname at any level.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	if strings.Contains(pattern, "/") {
+		b.WriteString("^")
+	} else {
+		b.WriteString("(^|/)")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					b.WriteString("(.*/)?") // Zero or more directories.
+					continue
+				}
+				b.WriteString(".*")
+				continue
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid glob pattern %s: missing ]", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %s: %s", pattern, err)
+	}
+
+	return re, nil
+}
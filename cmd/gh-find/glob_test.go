@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"*.tf", "main.tf", true},
+		{"*.tf", "infra/prod/main.tf", true},
+		{"*.tf", "main.tfvars", false},
+		{"**/*.tf", "main.tf", true},
+		{"**/*.tf", "infra/prod/main.tf", true},
+		{"infra/*.tf", "infra/main.tf", true},
+		{"infra/*.tf", "infra/prod/main.tf", false},
+		{"infra/**", "infra/prod/main.tf", true},
+		{"infra/**/main.tf", "infra/main.tf", true},
+		{"infra/**/main.tf", "infra/a/b/main.tf", true},
+		{".github/workflows/*.y?ml", ".github/workflows/ci.yaml", true},
+		{".github/workflows/*.y?ml", ".github/workflows/ci.yml", false},
+		{"Dockerfile*", "svc/Dockerfile.prod", true},
+		{"[Mm]akefile", "Makefile", true},
+		{"[!M]akefile", "Makefile", false},
+		{"a+b.txt", "a+b.txt", true},
+		{"a+b.txt", "aab.txt", false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(fmt.Sprint(tt.pattern, " ", tt.path), func(t *testing.T) {
+			t.Parallel()
+
+			re, err := globRegexp(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tt.match, re.MatchString(tt.path); want != got {
+				t.Errorf("Expected %v got %v (%s)", want, got, re)
+			}
+		})
+	}
+
+	if _, err := globRegexp("[a"); err == nil {
+		t.Errorf("Expected an error")
+	}
+}
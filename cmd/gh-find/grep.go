@@ -16,7 +16,14 @@ type grepResults struct {
 	matches  []grepMatch
 }
 
-func grep(contents io.Reader, pattern *regexp.Regexp, limit int) (*grepResults, error) {
+// Binary files handling modes.
+const (
+	binarySkip  = "skip"  // Skip binary files.
+	binaryMatch = "match" // Report that a binary file matches without printing lines.
+	binaryText  = "text"  // Treat binary files as text.
+)
+
+func grep(contents io.Reader, pattern *regexp.Regexp, limit int, binary string) (*grepResults, error) {
 	if contents == nil || pattern == nil {
 		return &grepResults{}, nil
 	}
@@ -24,9 +31,22 @@ func grep(contents io.Reader, pattern *regexp.Regexp, limit int) (*grepResults,
 	reader := bufio.NewReader(contents)
 	chunk, _ := reader.Peek(256)
 	for i := 0; i < len(chunk); i++ {
-		if chunk[i] == 0 {
+		if chunk[i] != 0 {
+			continue
+		}
+
+		switch binary {
+		case binaryText:
+		case binaryMatch:
+			results := &grepResults{isBinary: true}
+			if pattern.MatchReader(reader) {
+				results.matches = []grepMatch{{}}
+			}
+			return results, nil
+		default:
 			return &grepResults{isBinary: true}, nil // Skip if the contents is binary.
 		}
+		break
 	}
 	chunk = nil
 
@@ -51,3 +71,16 @@ func grep(contents io.Reader, pattern *regexp.Regexp, limit int) (*grepResults,
 
 	return results, nil
 }
+
+// onlyMatching returns the matched parts of the line
+// or the capture group of each match if the group is given.
+func onlyMatching(line string, pattern *regexp.Regexp, group int) []string {
+	var parts []string
+	for _, submatches := range pattern.FindAllStringSubmatch(line, -1) {
+		if group < len(submatches) && submatches[group] != "" {
+			parts = append(parts, submatches[group])
+		}
+	}
+
+	return parts
+}
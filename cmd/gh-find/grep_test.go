@@ -14,6 +14,7 @@ func TestGrep(t *testing.T) {
 		input   []byte
 		regex   *regexp.Regexp
 		limit   int
+		binary  string
 		results *grepResults
 	}{
 		{
@@ -86,6 +87,31 @@ func TestGrep(t *testing.T) {
 			regex:   regexp.MustCompile("foo"),
 			results: &grepResults{isBinary: true},
 		},
+		{
+			desc:    "binary match",
+			input:   []byte("\x00\x01foo\x00bar"),
+			regex:   regexp.MustCompile("foo"),
+			binary:  binaryMatch,
+			results: &grepResults{isBinary: true, matches: []grepMatch{{}}},
+		},
+		{
+			desc:    "binary no match",
+			input:   []byte("\x00\x01foo\x00bar"),
+			regex:   regexp.MustCompile("baz"),
+			binary:  binaryMatch,
+			results: &grepResults{isBinary: true},
+		},
+		{
+			desc:   "binary as text",
+			input:  []byte("\x00\x01\nfoo\x00bar\n"),
+			regex:  regexp.MustCompile("foo"),
+			binary: binaryText,
+			results: &grepResults{
+				matches: []grepMatch{
+					{line: "foo\x00bar", lineno: int64(2)},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -97,7 +123,7 @@ func TestGrep(t *testing.T) {
 			if tt.input != nil {
 				reader = bytes.NewReader(tt.input)
 			}
-			got, err := grep(reader, tt.regex, tt.limit)
+			got, err := grep(reader, tt.regex, tt.limit, tt.binary)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -107,3 +133,55 @@ func TestGrep(t *testing.T) {
 		})
 	}
 }
+
+func TestOnlyMatching(t *testing.T) {
+	tests := []struct {
+		desc  string
+		line  string
+		regex *regexp.Regexp
+		group int
+		parts []string
+	}{
+		{
+			desc:  "no match",
+			line:  "foo",
+			regex: regexp.MustCompile("bar"),
+		},
+		{
+			desc:  "whole match",
+			line:  "image: nginx:1.19",
+			regex: regexp.MustCompile(`nginx:\S+`),
+			parts: []string{"nginx:1.19"},
+		},
+		{
+			desc:  "multiple matches",
+			line:  "FROM golang:1.17 AS build FROM alpine:3.14",
+			regex: regexp.MustCompile(`\w+:[\d.]+`),
+			parts: []string{"golang:1.17", "alpine:3.14"},
+		},
+		{
+			desc:  "group",
+			line:  "github.com/aws/aws-sdk-go v1.35.0",
+			regex: regexp.MustCompile(`aws-sdk-go (v[\d.]+)`),
+			group: 1,
+			parts: []string{"v1.35.0"},
+		},
+		{
+			desc:  "empty group",
+			line:  "foo",
+			regex: regexp.MustCompile(`foo(bar)?`),
+			group: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if want, got := tt.parts, onlyMatching(tt.line, tt.regex, tt.group); !reflect.DeepEqual(want, got) {
+				t.Errorf("Expected %v got %v", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// printGrouped buffers results of the current repository
+// and writes them as a group once the repository changes.
+func (f *finder) printGrouped(r *result) error {
+	if len(f.results) > 0 && f.results[0].Repo != r.Repo {
+		if err := f.writeGroup(f.results); err != nil {
+			return err
+		}
+		f.results = nil
+	}
+	f.results = append(f.results, r)
+
+	return nil
+}
+
+// flushGroups writes buffered results grouped by the repository
+// in the order repositories first appear.
+func (f *finder) flushGroups() error {
+	for _, group := range repoGroups(f.results) {
+		if err := f.writeGroup(group); err != nil {
+			return err
+		}
+	}
+	f.results = nil
+
+	return nil
+}
+
+// repoGroups groups results by the repository keeping their relative order.
+func repoGroups(results []*result) [][]*result {
+	var (
+		groups [][]*result
+		index  = map[string]int{}
+	)
+	for _, r := range results {
+		i, ok := index[r.Repo]
+		if !ok {
+			i = len(groups)
+			index[r.Repo] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], r)
+	}
+
+	return groups
+}
+
+// writeGroup writes the repository header with the number of matches
+// followed by indented results of the repository.
+func (f *finder) writeGroup(group []*result) error {
+	if len(group) == 0 {
+		return nil
+	}
+
+	var lines []string
+	for _, r := range group {
+		if r.Path == "" {
+			continue // A repository with no matches.
+		}
+		if f.config.colorize {
+			r = f.colorize(r)
+		}
+		fields := formatResult(r, f.config.listDetails, f.config.url)
+		if !f.config.url || r.URL == "" {
+			fields = fields[1:] // Strip the repository name.
+		}
+		lines = append(lines, "  "+joinFields(fields, " "))
+	}
+
+	repo := group[0].Repo
+	if f.config.colorize {
+		repo = colorRepo + repo + colorReset
+	}
+	header := fmt.Sprintf("%s (%d %s)", repo, len(lines), plural(len(lines), "match", "matches"))
+	if len(lines) == 0 {
+		header = repo
+	}
+
+	_, err := fmt.Fprintln(f.stdout, strings.Join(append([]string{header}, lines...), "\n"))
+	return err
+}
+
+// plural returns the singular or the plural form depending on n.
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+
+	return plural
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGroupByRepo(t *testing.T) {
+	results := []*result{
+		{Repo: "foo/a", Path: "go.mod"},
+		{Repo: "foo/a", Path: "cmd/go.mod"},
+		{Repo: "foo/b", Path: "main.go", LineNo: 3, Line: "import \"fmt\""},
+		{Repo: "foo/c"}, // No matches.
+	}
+	want := "foo/a (2 matches)\n" +
+		"  go.mod\n" +
+		"  cmd/go.mod\n" +
+		"foo/b (1 match)\n" +
+		"  main.go 3 import \"fmt\"\n" +
+		"foo/c\n"
+
+	t.Run("streaming", func(t *testing.T) {
+		out := nopCloser{&bytes.Buffer{}}
+		f := &finder{config: config{format: formatText, groupByRepo: true}, stdout: out}
+		for _, r := range results {
+			if err := f.print(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := f.flush(); err != nil {
+			t.Fatal(err)
+		}
+		if got := out.String(); want != got {
+			t.Errorf("Expected %q got %q", want, got)
+		}
+	})
+
+	t.Run("sorted", func(t *testing.T) {
+		out := nopCloser{&bytes.Buffer{}}
+		f := &finder{config: config{format: formatText, groupByRepo: true, sort: sortRepo}, stdout: out}
+		for i := len(results) - 1; i >= 0; i-- {
+			if err := f.print(results[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := f.flush(); err != nil {
+			t.Fatal(err)
+		}
+		want := "foo/a (2 matches)\n" +
+			"  cmd/go.mod\n" +
+			"  go.mod\n" +
+			"foo/b (1 match)\n" +
+			"  main.go 3 import \"fmt\"\n" +
+			"foo/c\n"
+		if got := out.String(); want != got {
+			t.Errorf("Expected %q got %q", want, got)
+		}
+	})
+}
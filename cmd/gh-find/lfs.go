@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// Git LFS pointer files are small text files that replace the contents of
+// files tracked with Git LFS. See https://github.com/git-lfs/git-lfs/blob/main/docs/spec.md.
+const (
+	lfsVersion        = "version https://git-lfs.github.com/spec/v1"
+	maxLFSPointerSize = 1024
+)
+
+// resultLFS represents a Git LFS pointer.
+type resultLFS struct {
+	OID  string `json:"oid"`
+	Size int64  `json:"size"` // The size of the actual file.
+}
+
+// parseLFSPointer parses the Git LFS pointer.
+// It returns nil if the contents is not a valid pointer.
+func parseLFSPointer(r io.Reader) *resultLFS {
+	var (
+		lfs     resultLFS
+		scanner = bufio.NewScanner(io.LimitReader(r, maxLFSPointerSize))
+		err     error
+	)
+	for i := 0; scanner.Scan(); i++ {
+		line := scanner.Text()
+		if i == 0 {
+			if line != lfsVersion {
+				return nil
+			}
+			continue
+		}
+
+		parts := strings.SplitN(line, " ", 2)
+		if len(parts) != 2 {
+			return nil
+		}
+		switch parts[0] {
+		case "oid":
+			lfs.OID = parts[1]
+		case "size":
+			if lfs.Size, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+				return nil
+			}
+		}
+	}
+	if scanner.Err() != nil || lfs.OID == "" {
+		return nil
+	}
+
+	return &lfs
+}
+
+// isLFS checks if the path is tracked with Git LFS according to .gitattributes.
+func isLFS(path string, attrs []gitattribute) bool {
+	value, _ := attribute(path, attrLFS, attrs)
+	return value
+}
+
+// getLFSPointer reads the Git LFS pointer from the blob if the entry is tracked with Git LFS.
+func (f *finder) getLFSPointer(ctx context.Context, repo *github.Repository, entry *github.TreeEntry, attrs []gitattribute) (*resultLFS, error) {
+	if entry.GetSize() > maxLFSPointerSize || !isLFS(entry.GetPath(), attrs) {
+		return nil, nil
+	}
+
+	blob, _, err := f.gh.Git.GetBlobRaw(ctx, repo.GetOwner().GetLogin(), repo.GetName(), entry.GetSHA())
+	if err != nil {
+		return nil, err
+	}
+
+	return parseLFSPointer(bytes.NewReader(blob)), nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLFSPointer(t *testing.T) {
+	tests := []struct {
+		desc     string
+		contents string
+		want     *resultLFS
+	}{
+		{
+			desc: "pointer",
+			contents: "version https://git-lfs.github.com/spec/v1\n" +
+				"oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\n" +
+				"size 12345\n",
+			want: &resultLFS{OID: "sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393", Size: 12345},
+		},
+		{
+			desc:     "not a pointer",
+			contents: "package main\n",
+		},
+		{
+			desc:     "no oid",
+			contents: "version https://git-lfs.github.com/spec/v1\nsize 12345\n",
+		},
+		{
+			desc:     "invalid size",
+			contents: "version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize big\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if want, got := tt.want, parseLFSPointer(strings.NewReader(tt.contents)); !reflect.DeepEqual(want, got) {
+				t.Errorf("Expected %v got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestIsLFS(t *testing.T) {
+	attrs, err := parseGitattributes(strings.NewReader("*.psd filter=lfs diff=lfs merge=lfs -text\nassets/keep.psd -filter\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"design.psd", true},
+		{"assets/logo.psd", true},
+		{"assets/keep.psd", false},
+		{"main.go", false},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.want, isLFS(tt.path, attrs); want != got {
+			t.Errorf("%s: expected %v got %v", tt.path, want, got)
+		}
+	}
+}
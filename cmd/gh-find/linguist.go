@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// vendoredRegexp matches paths of vendored files and directories.
+// A subset of GitHub linguist vendor.yml rules.
+var vendoredRegexp = regexp.MustCompile(strings.Join([]string{
+	`(^|/)vendor(/|$)`,
+	`(^|/)node_modules(/|$)`,
+	`(^|/)bower_components(/|$)`,
+	`(^|/)Godeps/_workspace(/|$)`,
+	`(^|/)third[-_]?party(/|$)`,
+	`(^|/)\.yarn/(releases|plugins|sdks|versions|unplugged)(/|$)`,
+	`(^|/)Carthage(/|$)`,
+	`(^|/)Pods(/|$)`,
+	`^deps(/|$)`,
+	`(\.|-)min\.(js|css)$`,
+	`(^|/)gradlew(\.bat)?$`,
+	`(^|/)mvnw(\.cmd)?$`,
+}, "|"))
+
+// generatedRegexp matches paths of generated files.
+// A subset of GitHub linguist generated.rb rules that don't require the file contents.
+var generatedRegexp = regexp.MustCompile(strings.Join([]string{
+	`(^|/)package-lock\.json$`,
+	`(^|/)npm-shrinkwrap\.json$`,
+	`(^|/)yarn\.lock$`,
+	`(^|/)pnpm-lock\.yaml$`,
+	`(^|/)composer\.lock$`,
+	`(^|/)Cargo\.lock$`,
+	`(^|/)Gemfile\.lock$`,
+	`(^|/)Pipfile\.lock$`,
+	`(^|/)poetry\.lock$`,
+	`(^|/)Gopkg\.lock$`,
+	`(^|/)glide\.lock$`,
+	`(^|/)go\.sum$`,
+	`(^|/)flake\.lock$`,
+	`\.pb\.go$`,
+	`\.pb\.(cc|h)$`,
+	`_pb2(_grpc)?\.py$`,
+	`\.pb\.swift$`,
+	`(^|/)zz_generated\.[^/]+\.go$`,
+	`_generated\.go$`,
+	`\.designer\.(cs|vb)$`,
+	`(^|/)__generated__/`,
+}, "|"))
+
+// Linguist attributes.
+const (
+	attrVendored  = "linguist-vendored"
+	attrGenerated = "linguist-generated"
+	attrLFS       = "filter=lfs" // Not a linguist attribute but it's read along.
+)
+
+// gitattribute represents a linguist or Git LFS attribute set for a pattern in .gitattributes.
+type gitattribute struct {
+	pattern *regexp.Regexp
+	name    string
+	value   bool
+}
+
+// parseGitattributes parses linguist-vendored, linguist-generated
+// and filter=lfs attributes from the .gitattributes file.
+func parseGitattributes(r io.Reader) ([]gitattribute, error) {
+	var attrs []gitattribute
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		var pattern *regexp.Regexp
+		for _, field := range fields[1:] {
+			name, value, ok := parseAttribute(field)
+			switch {
+			case field == attrLFS:
+				name, value, ok = attrLFS, true, true
+			case ok && name == "filter": // -filter.
+				name = attrLFS
+			}
+			if !ok || (name != attrVendored && name != attrGenerated && name != attrLFS) {
+				continue
+			}
+			if pattern == nil {
+				var err error
+				pattern, err = globRegexp(strings.TrimPrefix(fields[0], "/"))
+				if err != nil {
+					break // Skip invalid patterns.
+				}
+			}
+			attrs = append(attrs, gitattribute{pattern: pattern, name: name, value: value})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return attrs, nil
+}
+
+// parseAttribute parses the attribute in the forms name, -name, name=true or name=false.
+// Unspecified (!name) and other values are not supported.
+func parseAttribute(s string) (string, bool, bool) {
+	if strings.HasPrefix(s, "-") {
+		return s[1:], false, true
+	}
+
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) == 1 {
+		return s, !strings.HasPrefix(s, "!"), !strings.HasPrefix(s, "!")
+	}
+	switch strings.ToLower(parts[1]) {
+	case "true":
+		return parts[0], true, true
+	case "false":
+		return parts[0], false, true
+	default:
+		return "", false, false
+	}
+}
+
+// attribute returns the value of the attribute for the path.
+// The last matching pattern wins.
+func attribute(path, name string, attrs []gitattribute) (bool, bool) {
+	for i := len(attrs) - 1; i >= 0; i-- {
+		if attrs[i].name == name && attrs[i].pattern.MatchString(path) {
+			return attrs[i].value, true
+		}
+	}
+
+	return false, false
+}
+
+// isVendored checks if the path is vendored respecting .gitattributes overrides.
+func isVendored(path string, attrs []gitattribute) bool {
+	if value, ok := attribute(path, attrVendored, attrs); ok {
+		return value
+	}
+
+	return vendoredRegexp.MatchString(path)
+}
+
+// isGenerated checks if the path is generated respecting .gitattributes overrides.
+func isGenerated(path string, attrs []gitattribute) bool {
+	if value, ok := attribute(path, attrGenerated, attrs); ok {
+		return value
+	}
+
+	return generatedRegexp.MatchString(path)
+}
+
+// getGitattributes reads linguist and Git LFS attributes from the .gitattributes file
+// in the root of the repository if there is one.
+func (f *finder) getGitattributes(ctx context.Context, repo *github.Repository, branch string, entries []*github.TreeEntry) ([]gitattribute, error) {
+	for _, entry := range entries {
+		if entry.GetPath() != ".gitattributes" || entry.GetType() != "blob" {
+			continue
+		}
+
+		opts := &github.RepositoryContentGetOptions{Ref: branch}
+		contents, err := f.gh.Repositories.DownloadContents(ctx, repo.GetOwner().GetLogin(), repo.GetName(), entry.GetPath(), opts)
+		if err != nil {
+			return nil, err
+		}
+		defer contents.Close()
+
+		return parseGitattributes(contents)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLinguist(t *testing.T) {
+	attrs, err := parseGitattributes(strings.NewReader(`
+# Overrides
+*.go text eol=lf
+internal/vendor/** -linguist-vendored
+third_party/** linguist-vendored=false
+api/*.go linguist-generated
+/docs/** linguist-vendored linguist-generated=true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path      string
+		vendored  bool
+		generated bool
+	}{
+		{"main.go", false, false},
+		{"vendor/github.com/foo/bar/bar.go", true, false},
+		{"vendor", true, false},
+		{"web/node_modules/left-pad/index.js", true, false},
+		{"static/jquery.min.js", true, false},
+		{"internal/vendor/foo.go", false, false},
+		{"third_party/foo.c", false, false},
+		{"package-lock.json", false, true},
+		{"web/yarn.lock", false, true},
+		{"go.sum", false, true},
+		{"proto/foo.pb.go", false, true},
+		{"api/handlers.go", false, true},
+		{"api/v1/handlers.go", false, false},
+		{"docs/index.md", true, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			if want, got := tt.vendored, isVendored(tt.path, attrs); want != got {
+				t.Errorf("Expected vendored %v got %v", want, got)
+			}
+			if want, got := tt.generated, isGenerated(tt.path, attrs); want != got {
+				t.Errorf("Expected generated %v got %v", want, got)
+			}
+		})
+	}
+}
@@ -2,14 +2,16 @@ package main
 
 import (
 	"context"
+	"encoding/csv"
 	"flag"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path"
 	"regexp"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/google/go-github/v32/github"
 	"github.com/pmatseykanets/gh-tools/auth"
@@ -24,40 +26,117 @@ func usage() {
 	usage := `Walk file hierarchies across GitHub repositories
 
 Usage: gh-find [flags] [owner][/repo]
+       gh-find [flags] -repo-file=<path>
   owner         Repository owner (user or organization)
   repo          Repository name
 
 Flags:
-  -archived          Include archived repositories
-  -help, h           Print this information and exit
-  -branch=           The branch name if different from the default
-  -grep=             The pattern to match the file contents. Implies
-                      -type f
-  -list-details      List details (file type, author, size, last commit date)
-  -max-depth         Descend at most n directory levels
-  -max-grep-results= Limit the number of grep results
-  -max-repo-results= Limit the number of matched entries per repository
-  -max-results=      Limit the number of matched entries
-  -min-depth=        Descend at least n directory levels
-  -name=             The pattern to match the last component of the pathname
-  -no-fork           Don't include fork repositories
-  -no-grep=          The pattern to reject the file contents. Implies
-                       -type f
-  -no-matches        List repositories with no matches. Implies
-                       -max-results 0
-                       -max-grep-results 1
-                       -max-repo-results 1
-  -no-name=          The pattern to reject the last component of the pathname
-  -no-path=          The pattern to reject the pathname
-  -no-private        Don't include private repositories
-  -no-public         Don't include public repositories
-  -no-repo=          The pattern to reject repository names
-  -path=             The pattern to match the pathname
-  -repo=             The pattern to match repository names
-  -size=             Limit results based on the file size [+-]<d><u>
-  -token             Prompt for an Access Token
-  -type=             The entry type f - file, d - directory
-  -version           Print the version and exit
+  -F                   Treat -grep, -no-grep, -name and -no-name patterns as fixed strings
+  -archived            Include archived repositories
+  -group=              Print only the capture group n of the matched parts. Requires -o
+  -group-by-repo       Print the repository name with the number of matches once
+                         followed by indented matches
+  -help, h             Print this information and exit
+  -author=             The pattern to match the GitHub login, name or email of the author
+                         of the last commit touching the entry
+  -binary=             How to handle binary files when grepping skip (default), match
+                         (report binary file matches) or text (treat as text)
+  -branch=             The branch name if different from the default
+  -checkpoint=         Record completed repositories and their match counts in the file
+                         to be able to resume an interrupted run with -resume
+  -color=              Colorize the output auto (default), always or never.
+                         The auto mode respects NO_COLOR and colorizes only terminal output
+  -committer=          The pattern to match the GitHub login, name or email of the committer
+                         of the last commit touching the entry
+  -duplicates          Report matched entries with identical contents (the same git SHA)
+                         appearing in more than one repository or path. In the text format
+                         groups are separated by an empty line
+  -empty               Match empty files and directories that contain only empty files
+                         and placeholder files (.gitkeep, .keep)
+  -exec=               Run the command for each matched entry instead of printing it.
+                         Quotes and backslashes keep spaces within arguments.
+                         {} - the pathname
+                         {repo} - the repository name
+                         {file} - the path to a local copy of the file contents
+  -format=             The output format text (default), json (one object per line)
+                         or csv (with a header row)
+  -format-template=    The Go template to format each result with (e.g. '{{.Repo}}\t{{.Path}}').
+                         Fields: Repo, Path, Type, Mode, Size, SHA, URL, LineNo, Line,
+                         Commit.SHA, Commit.Author, Commit.Date
+  -glob=               The shell-style pattern to match the pathname (e.g. **/*.tf).
+                         Patterns without / match the last component of the pathname
+  -grep=               The pattern to match the file contents. Implies
+                         -type f
+  -language=           Only include repositories with the primary language
+  -large-files=        Report files larger than the size <d><u> (e.g. 10M) and Git LFS pointers
+                         of files tracked with filter=lfs in .gitattributes. Implies -type f
+  -list-details        List details (file type, author, size, last commit date)
+  -max-depth           Descend at most n directory levels
+  -max-grep-results=   Limit the number of grep results
+  -max-grep-size=      Skip grepping files larger than the size <d><u> (e.g. 10M).
+                         Skipped files are reported to stderr
+  -max-repo-results=   Limit the number of matched entries per repository
+  -max-results=        Limit the number of matched entries
+  -mtime=              Limit results based on the age of the last commit touching the entry
+                         [+-]<d><u> (e.g. +1y, -30d, -12h)
+  -min-depth=          Descend at least n directory levels
+  -missing-path=       List repositories missing the path relative to the repository root.
+                         Can be repeated to accept alternative locations. Implies -no-matches
+  -name=               The pattern to match the last component of the pathname
+  -no-cache            Don't cache repository trees in the user cache directory
+                         (e.g. ~/.cache/gh-tools) between runs
+  -no-fork             Don't include fork repositories
+  -no-generated        Don't include generated files (e.g. lock files, *.pb.go) using GitHub
+                         linguist rules and linguist-generated attributes in .gitattributes
+  -no-glob=            The shell-style pattern to reject the pathname
+  -no-grep=            The pattern to reject the file contents. Implies
+                         -type f
+  -no-matches          List repositories with no matches. Implies
+                         -max-results 0
+                         -max-grep-results 1
+                         -max-repo-results 1
+  -no-name=            The pattern to reject the last component of the pathname
+  -no-path=            The pattern to reject the pathname
+  -no-vendored         Don't include vendored files (e.g. vendor/, node_modules/) using GitHub
+                         linguist rules and linguist-vendored attributes in .gitattributes
+  -no-private          Don't include private repositories
+  -no-public           Don't include public repositories
+  -no-repo=            The pattern to reject repository names
+  -o                   Print only the matched parts of the grep lines, each on its own line
+  -path=               The pattern to match the pathname
+  -perm=               The entry mode regular, executable, symlink, submodule, directory
+                         or the octal git mode (e.g. 100755)
+  -print0              Separate records with NUL and fields with tabs (e.g. for xargs -0)
+  -quiet               Don't report progress to stderr
+  -ref=                The branch, tag or commit SHA if different from the default branch
+  -repo=               The pattern to match repository names
+  -repo-file=          Read repository names (owner/repo) one per line from the file
+                         or stdin (-). Anything after the name on a line is ignored.
+                         Repository filters (e.g. -repo, -no-fork) apply to the listed ones
+  -repo-timeout=       Skip repositories taking longer than the duration (e.g. 5m).
+                         Skipped repositories are reported to stderr
+  -resume              Skip repositories completed by the run recorded in the -checkpoint file
+  -reverse             Reverse the sort order
+  -size=               Limit results based on the file size [+-]<d><u> or the inclusive range
+                         <d><u>..<d><u> (e.g. 1M..10M). Can be repeated, all should match
+  -sort=               Sort results by path, size, repo or mtime (the last commit date)
+  -start-path=         Walk only the subtree at the path (e.g. .github/workflows).
+                         -min-depth and -max-depth are relative to the path
+  -summary             Print per repository and overall totals (entries scanned, matches,
+                         bytes downloaded, API calls, elapsed time) to stderr
+  -tarball-threshold=  Download the repository tarball and grep files locally once
+                         the number of files to grep in the repository exceeds n.
+                         Default 10, 0 - always, -1 - never
+  -topic=              Only include repositories with the topic
+  -token               Prompt for an Access Token
+  -type=               The entry type f - file, d - directory
+  -url                 Print web URLs (with #L<n> anchors for grep matches) instead of
+                         repository names and paths in the text format
+  -use-search          Use the code search to preselect files to grep in the default branch.
+                         Falls back to walking the tree if the search results are incomplete
+                         The search matches whole tokens so it's used only if the literal is
+                         bounded by non-word characters, anchors or \b (e.g. \bsync\b)
+  -version             Print the version and exit
 `
 	fmt.Printf("gh-find version %s\n", version.Version)
 	fmt.Println(usage)
@@ -75,55 +154,113 @@ const (
 	typeDir  = "d"
 )
 
-type sizePredicate struct {
-	op    int   // <0 - less than, 0 - equals, >0 greater than
-	value int64 // Size in bytes
-}
+// parseSizePredicates parses the size predicate [+-]<d><u>
+// or the inclusive range <d><u>..<d><u>.
+func parseSizePredicates(s string) ([]*size.Predicate, error) {
+	if parts := strings.SplitN(s, "..", 2); len(parts) == 2 {
+		if parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid range %s", s)
+		}
+		min, err := size.Parse(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		max, err := size.Parse(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		if min > max {
+			return nil, fmt.Errorf("invalid range %s", s)
+		}
 
-func (p *sizePredicate) match(value int64) bool {
-	switch p.op {
-	case 0:
-		return value == p.value
-	case 1:
-		return value >= p.value
-	default:
-		return value <= p.value
+		return []*size.Predicate{{Op: 1, Value: min}, {Op: -1, Value: max}}, nil
 	}
+
+	p, err := size.ParsePredicate(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*size.Predicate{p}, nil
 }
 
 type config struct {
-	owner          string
-	repo           string
-	repoRegexp     *regexp.Regexp   // The pattern to match respository names.
-	branch         string           // The branch name if different from the default.
-	ftype          string           // The entry type f - file, d - directory.
-	minDepth       int              // Descend at least n directory levels.
-	maxDepth       int              // Descend at most n directory levels.
-	maxResults     int              // Limit the number of matched entries.
-	maxRepoResults int              // Limit the number of matched entries per repository.
-	nameRegexp     []*regexp.Regexp // The pattern to match the last component of the pathname.
-	noNameRegexp   []*regexp.Regexp // The pattern to reject the last component of the pathname.
-	pathRegexp     []*regexp.Regexp // The pattern to match the pathname.
-	noPathRegexp   []*regexp.Regexp // The pattern to reject the pathname.
-	grepRegexp     *regexp.Regexp   // The pattern to match the contents of matching files.
-	noGrepRegexp   *regexp.Regexp   // The pattern to reject the file contents.
-	token          bool             // Propmt for an access token.
-	size           *sizePredicate   // Limit results based on the file size [+-]<d><u>.
-	noMatches      bool             // List repositories with no matches.
-	maxGrepResults int              // Limit the number of grep results.
-	listDetails    bool             // List details.
-	archived       bool             // Include archived repositories.
-	noPrivate      bool             // Don't include private repositories.
-	noPublic       bool             // Don't include public repositories.
-	noFork         bool             // Don't include fork repositories.
-	noRepoRegexp   *regexp.Regexp   // The pattern to reject repository names.
+	owner            string
+	repo             string
+	repoRegexp       *regexp.Regexp     // The pattern to match respository names.
+	branch           string             // The branch, tag or commit SHA if different from the default.
+	ftype            string             // The entry type f - file, d - directory.
+	minDepth         int                // Descend at least n directory levels.
+	maxDepth         int                // Descend at most n directory levels.
+	maxResults       int                // Limit the number of matched entries.
+	maxRepoResults   int                // Limit the number of matched entries per repository.
+	nameRegexp       []*regexp.Regexp   // The pattern to match the last component of the pathname.
+	noNameRegexp     []*regexp.Regexp   // The pattern to reject the last component of the pathname.
+	pathRegexp       []*regexp.Regexp   // The pattern to match the pathname.
+	noPathRegexp     []*regexp.Regexp   // The pattern to reject the pathname.
+	grepRegexp       *regexp.Regexp     // The pattern to match the contents of matching files.
+	noGrepRegexp     *regexp.Regexp     // The pattern to reject the file contents.
+	token            bool               // Propmt for an access token.
+	sizes            []*size.Predicate  // Limit results based on the file size. All predicates should match.
+	noMatches        bool               // List repositories with no matches.
+	maxGrepResults   int                // Limit the number of grep results.
+	listDetails      bool               // List details.
+	archived         bool               // Include archived repositories.
+	noPrivate        bool               // Don't include private repositories.
+	noPublic         bool               // Don't include public repositories.
+	noFork           bool               // Don't include fork repositories.
+	noRepoRegexp     *regexp.Regexp     // The pattern to reject repository names.
+	format           string             // The output format.
+	print0           bool               // Separate records with NUL and fields with tabs.
+	exec             []string           // The command to run for each matched entry.
+	mtime            *agePredicate      // Limit results based on the age of the last commit.
+	authorRegexp     *regexp.Regexp     // The pattern to match the author of the last commit.
+	committerRegexp  *regexp.Regexp     // The pattern to match the committer of the last commit.
+	modes            []string           // The entry modes to match.
+	tarballThreshold int                // Download the repository tarball after n files to grep.
+	useSearch        bool               // Use the code search to preselect files to grep.
+	noCache          bool               // Don't cache repository trees.
+	quiet            bool               // Don't report progress.
+	topics           []string           // Only include repositories with the topics.
+	languages        []string           // Only include repositories with the primary languages.
+	startPath        string             // Walk only the subtree at the path.
+	summary          bool               // Print the summary.
+	repoTimeout      time.Duration      // Skip repositories taking longer than the duration.
+	duplicates       bool               // Report identical entries across repositories and paths.
+	url              bool               // Print web URLs instead of repository names and paths.
+	repoNames        []string           // The full names of repositories to use instead of searching.
+	checkpointFile   string             // The file to record the progress in.
+	empty            bool               // Match empty files and directories.
+	groupByRepo      bool               // Group results by the repository.
+	resume           bool               // Resume the run recorded in the checkpoint file.
+	sort             string             // Sort results by the key.
+	reverse          bool               // Reverse the sort order.
+	colorize         bool               // Colorize the output.
+	template         *template.Template // The template to format results with.
+	onlyMatching     bool               // Print only the matched parts of the grep lines.
+	group            int                // Print only the capture group of the matched parts.
+	noVendored       bool               // Don't include vendored files.
+	noGenerated      bool               // Don't include generated files.
+	binary           string             // How to handle binary files.
+	maxGrepSize      int64              // Skip grepping files larger than the size in bytes.
+	largeFiles       int64              // Report files larger than the size in bytes and Git LFS pointers.
 }
 
 type finder struct {
-	gh     *github.Client
-	config config
-	stdout io.WriteCloser
-	stderr io.WriteCloser
+	gh         *github.Client
+	config     config
+	archiveDir string             // The directory the repository tarball is extracted to.
+	noArchive  bool               // Don't try to download the repository tarball.
+	downloads  int                // The number of files to grep in the repository.
+	timedOut   bool               // The repository exceeded the repo-timeout.
+	cancelRepo context.CancelFunc // Cancels the repository context.
+	results    []*result          // Buffered results to sort.
+	cache      *treeCache
+	csv        *csv.Writer // The csv writer once the header is written.
+	summary    *summary    // Run totals if requested.
+	checkpoint *checkpoint // The progress of the run if requested.
+	stdout     io.WriteCloser
+	stderr     io.WriteCloser
 }
 
 type stringList []string
@@ -146,38 +283,85 @@ func readConfig() (config, error) {
 		os.Exit(1)
 	}
 
-	config := config{}
+	config := config{
+		format:           formatText,
+		binary:           binarySkip,
+		tarballThreshold: 10,
+	}
 
 	var (
-		showVersion, showHelp             bool
-		grep, noGrep, repo, noRepo, fsize string
-		name, path, noName, noPath        stringList
-		err                               error
+		showVersion, showHelp, fixedStrings                             bool
+		grep, noGrep, repo, noRepo, execCmd, ref, mtime                 string
+		author, committer                                               string
+		color                                                           = colorAuto
+		formatTemplate, maxGrepSize, largeFiles, repoFile               string
+		name, path, noName, noPath, glob, noGlob, perm, topic, language stringList
+		fsize, missingPath                                              stringList
+		err                                                             error
 	)
+	flag.BoolVar(&fixedStrings, "F", false, "Treat grep and name patterns as fixed strings")
+	flag.StringVar(&config.checkpointFile, "checkpoint", "", "Record the progress in the file to be able to resume the run")
 	flag.BoolVar(&config.archived, "archived", config.archived, "Include archived repositories")
+	flag.StringVar(&config.binary, "binary", config.binary, "How to handle binary files when grepping skip, match or text")
 	flag.StringVar(&config.branch, "branch", "", "The branch name if different from the default")
+	flag.StringVar(&color, "color", color, "Colorize the output auto, always or never")
+	flag.BoolVar(&config.empty, "empty", config.empty, "Match empty files and directories")
+	flag.StringVar(&execCmd, "exec", "", "Run the command for each matched entry")
+	flag.StringVar(&formatTemplate, "format-template", "", "The Go template to format each result with")
+	flag.StringVar(&config.format, "format", config.format, "The output format text, json or csv")
+	flag.BoolVar(&config.groupByRepo, "group-by-repo", config.groupByRepo, "Group results by the repository")
+	flag.IntVar(&config.group, "group", 0, "Print only the capture group n of the matched parts")
 	flag.BoolVar(&showHelp, "help", false, "Print this information and exit")
+	flag.Var(&glob, "glob", "The shell-style pattern to match the pathname")
 	flag.StringVar(&grep, "grep", "", "The pattern to match the file contents")
+	flag.Var(&language, "language", "Only include repositories with the primary language")
 	flag.BoolVar(&config.listDetails, "list-details", config.listDetails, "List details (file type, author, size, last commit date)")
 	flag.IntVar(&config.maxDepth, "max-depth", 0, "Descend at most n directory levels")
 	flag.IntVar(&config.maxGrepResults, "max-grep-results", 0, "Limit the number of grep results.")
 	flag.IntVar(&config.maxResults, "max-results", 0, "Limit the number of matched entries")
+	flag.StringVar(&maxGrepSize, "max-grep-size", "", "Skip grepping files larger than the size <d><u>")
+	flag.StringVar(&largeFiles, "large-files", "", "Report files larger than the size <d><u> and Git LFS pointers")
 	flag.IntVar(&config.maxRepoResults, "max-repo-results", 0, "Limit the number of matched entries per repository")
+	flag.StringVar(&author, "author", "", "The pattern to match the author of the last commit")
+	flag.StringVar(&committer, "committer", "", "The pattern to match the committer of the last commit")
+	flag.StringVar(&mtime, "mtime", "", "Limit results based on the age of the last commit [+-]<d><u>")
 	flag.IntVar(&config.minDepth, "min-depth", 0, "Descend at least n directory levels")
 	flag.Var(&name, "name", "The pattern to match the last component of the pathname")
 	flag.BoolVar(&config.noFork, "no-fork", config.noFork, "Don't include fork repositories")
+	flag.BoolVar(&config.noGenerated, "no-generated", config.noGenerated, "Don't include generated files")
+	flag.Var(&noGlob, "no-glob", "The shell-style pattern to reject the pathname")
+	flag.BoolVar(&config.noCache, "no-cache", config.noCache, "Don't cache repository trees")
 	flag.StringVar(&noGrep, "no-grep", "", "The pattern to reject the file contents")
+	flag.Var(&missingPath, "missing-path", "List repositories missing the path")
 	flag.BoolVar(&config.noMatches, "no-matches", config.noMatches, "List repositories with no matches")
 	flag.Var(&noName, "no-name", "The pattern to reject the last component of the pathname")
 	flag.Var(&noPath, "no-path", "The pattern to reject the pathname")
+	flag.BoolVar(&config.noVendored, "no-vendored", config.noVendored, "Don't include vendored files")
 	flag.BoolVar(&config.noPrivate, "no-private", config.noPrivate, "Don't include private repositories")
 	flag.BoolVar(&config.noPublic, "no-public", config.noPublic, "Don't include public repositories")
 	flag.StringVar(&noRepo, "no-repo", "", "The pattern to reject repository names")
+	flag.BoolVar(&config.onlyMatching, "o", config.onlyMatching, "Print only the matched parts of the grep lines")
 	flag.Var(&path, "path", "The pattern to match the pathname")
+	flag.Var(&perm, "perm", "The entry mode")
+	flag.BoolVar(&config.print0, "print0", config.print0, "Separate records with NUL and fields with tabs")
+	flag.BoolVar(&config.quiet, "quiet", config.quiet, "Don't report progress")
+	flag.StringVar(&ref, "ref", "", "The branch, tag or commit SHA if different from the default branch")
+	flag.StringVar(&repoFile, "repo-file", "", "Read repository names from the file")
 	flag.StringVar(&repo, "repo", "", "The pattern to match repository names")
-	flag.StringVar(&fsize, "size", "", "Limit results based on the file size [+-]<d><u>")
+	flag.BoolVar(&config.resume, "resume", config.resume, "Resume the run recorded in the checkpoint file")
+	flag.BoolVar(&config.reverse, "reverse", config.reverse, "Reverse the sort order")
+	flag.BoolVar(&config.url, "url", config.url, "Print web URLs instead of repository names and paths")
+	flag.BoolVar(&config.duplicates, "duplicates", config.duplicates, "Report identical entries across repositories and paths")
+	flag.DurationVar(&config.repoTimeout, "repo-timeout", 0, "Skip repositories taking longer than the duration")
+	flag.BoolVar(&config.summary, "summary", config.summary, "Print the summary")
+	flag.StringVar(&config.startPath, "start-path", "", "Walk only the subtree at the path")
+	flag.Var(&fsize, "size", "Limit results based on the file size [+-]<d><u> or <d><u>..<d><u>")
+	flag.StringVar(&config.sort, "sort", "", "Sort results by path, size, repo or mtime")
+	flag.IntVar(&config.tarballThreshold, "tarball-threshold", config.tarballThreshold, "Download the repository tarball once the number of files to grep exceeds n")
+	flag.Var(&topic, "topic", "Only include repositories with the topic")
 	flag.BoolVar(&config.token, "token", config.token, "Prompt for Access Token")
 	flag.StringVar(&config.ftype, "type", "", "File type f - file, d - directory")
+	flag.BoolVar(&config.useSearch, "use-search", config.useSearch, "Use the code search to preselect files to grep")
 	flag.BoolVar(&showVersion, "version", showVersion, "Print version and exit")
 	flag.Usage = usage
 	flag.Parse()
@@ -204,23 +388,50 @@ func readConfig() (config, error) {
 		return config, fmt.Errorf("invalid owner or repository name %s", flag.Arg(0))
 	}
 
-	if config.owner == "" {
+	if repoFile != "" {
+		if flag.Arg(0) != "" {
+			return config, fmt.Errorf("repo-file and owner are mutually exclusive")
+		}
+		if config.repoNames, err = gh.ReadRepoListFile(repoFile); err != nil {
+			return config, fmt.Errorf("can't read repo file %s: %s", repoFile, err)
+		}
+		if len(config.repoNames) == 0 {
+			return config, fmt.Errorf("repo file %s is empty", repoFile)
+		}
+	}
+
+	if config.owner == "" && len(config.repoNames) == 0 {
 		return config, fmt.Errorf("owner is required")
 	}
 
+	if ref != "" {
+		if config.branch != "" {
+			return config, fmt.Errorf("branch and ref are mutually exclusive")
+		}
+		config.branch = ref
+	}
+
 	if config.noPrivate && config.noPublic {
 		return config, fmt.Errorf("no-private and no-public are mutually exclusive")
 	}
 
+	// Grep and name patterns are matched literally with -F.
+	compile := regexp.Compile
+	if fixedStrings {
+		compile = func(s string) (*regexp.Regexp, error) {
+			return regexp.Compile(regexp.QuoteMeta(s))
+		}
+	}
+
 	config.nameRegexp = make([]*regexp.Regexp, len(name))
 	for i, n := range name {
-		if config.nameRegexp[i], err = regexp.Compile(n); err != nil {
+		if config.nameRegexp[i], err = compile(n); err != nil {
 			return config, fmt.Errorf("invalid name pattern: %s: %s", n, err)
 		}
 	}
 	config.noNameRegexp = make([]*regexp.Regexp, len(noName))
 	for i, n := range noName {
-		if config.noNameRegexp[i], err = regexp.Compile(n); err != nil {
+		if config.noNameRegexp[i], err = compile(n); err != nil {
 			return config, fmt.Errorf("invalid no-name pattern: %s: %s", n, err)
 		}
 	}
@@ -238,6 +449,49 @@ func readConfig() (config, error) {
 		}
 	}
 
+	if len(missingPath) > 0 {
+		if len(path) > 0 || len(glob) > 0 {
+			return config, fmt.Errorf("missing-path, path and glob are mutually exclusive")
+		}
+		// Listing repositories with no entries matching any of the exact paths.
+		for _, p := range missingPath {
+			trimmed := strings.Trim(strings.TrimSpace(p), "/")
+			if trimmed == "" {
+				return config, fmt.Errorf("invalid missing-path %s", p)
+			}
+			config.pathRegexp = append(config.pathRegexp, regexp.MustCompile("^"+regexp.QuoteMeta(trimmed)+"$"))
+		}
+		config.noMatches = true
+	}
+
+	for _, g := range glob {
+		re, err := globRegexp(g)
+		if err != nil {
+			return config, err
+		}
+		config.pathRegexp = append(config.pathRegexp, re)
+	}
+	for _, g := range noGlob {
+		re, err := globRegexp(g)
+		if err != nil {
+			return config, err
+		}
+		config.noPathRegexp = append(config.noPathRegexp, re)
+	}
+
+	config.startPath = strings.Trim(strings.TrimSpace(config.startPath), "/")
+
+	for _, t := range topic {
+		if t = strings.TrimSpace(t); t != "" {
+			config.topics = append(config.topics, t)
+		}
+	}
+	for _, l := range language {
+		if l = strings.TrimSpace(l); l != "" {
+			config.languages = append(config.languages, l)
+		}
+	}
+
 	if repo != "" {
 		if config.repoRegexp, err = regexp.Compile(repo); err != nil {
 			return config, fmt.Errorf("invalid repo pattern: %s", err)
@@ -250,6 +504,64 @@ func readConfig() (config, error) {
 		}
 	}
 
+	switch config.format {
+	case formatText, formatJSON, formatCSV:
+	default:
+		return config, fmt.Errorf("invalid format: %s", config.format)
+	}
+	if formatTemplate != "" {
+		if config.format != formatText {
+			return config, fmt.Errorf("format and format-template are mutually exclusive")
+		}
+		if config.template, err = parseTemplate(formatTemplate); err != nil {
+			return config, fmt.Errorf("invalid format-template: %s", err)
+		}
+	}
+
+	switch color {
+	case colorAuto, colorAlways, colorNever:
+		config.colorize = config.format == formatText && useColor(color)
+	default:
+		return config, fmt.Errorf("invalid color: %s", color)
+	}
+
+	if config.print0 && config.format != formatText {
+		return config, fmt.Errorf("print0 requires the text format")
+	}
+
+	if execCmd != "" {
+		if config.exec, err = parseExec(execCmd); err != nil {
+			return config, err
+		}
+	}
+
+	switch config.sort {
+	case "", sortPath, sortSize, sortRepo, sortMtime:
+	default:
+		return config, fmt.Errorf("invalid sort: %s", config.sort)
+	}
+	if config.sort != "" && config.exec != nil {
+		return config, fmt.Errorf("sort and exec are mutually exclusive")
+	}
+	if config.reverse && config.sort == "" {
+		return config, fmt.Errorf("reverse requires sort")
+	}
+	if config.groupByRepo && (config.format != formatText || config.print0 || config.template != nil) {
+		return config, fmt.Errorf("group-by-repo requires the text format")
+	}
+	if config.groupByRepo && config.exec != nil {
+		return config, fmt.Errorf("group-by-repo and exec are mutually exclusive")
+	}
+	if config.groupByRepo && config.duplicates {
+		return config, fmt.Errorf("group-by-repo and duplicates are mutually exclusive")
+	}
+	if config.duplicates && config.exec != nil {
+		return config, fmt.Errorf("duplicates and exec are mutually exclusive")
+	}
+	if config.duplicates && config.noMatches {
+		return config, fmt.Errorf("duplicates and no-matches are mutually exclusive")
+	}
+
 	switch t := config.ftype; t {
 	case "", typeFile, typeDir: // Empty or valid.
 	default:
@@ -257,18 +569,34 @@ func readConfig() (config, error) {
 	}
 
 	if grep != "" {
-		if config.grepRegexp, err = regexp.Compile(grep); err != nil {
+		if config.grepRegexp, err = compile(grep); err != nil {
 			return config, fmt.Errorf("invalid grep pattern: %s", err)
 		}
 		config.ftype = typeFile // Implies file type.
 	}
 	if noGrep != "" {
-		if config.noGrepRegexp, err = regexp.Compile(noGrep); err != nil {
+		if config.noGrepRegexp, err = compile(noGrep); err != nil {
 			return config, fmt.Errorf("invalid no-grep pattern: %s", err)
 		}
 		config.ftype = typeFile // Implies file type.
 	}
 
+	switch config.binary {
+	case binarySkip, binaryMatch, binaryText:
+	default:
+		return config, fmt.Errorf("invalid binary: %s", config.binary)
+	}
+
+	if config.onlyMatching && config.grepRegexp == nil {
+		return config, fmt.Errorf("o requires grep")
+	}
+	if config.group != 0 && !config.onlyMatching {
+		return config, fmt.Errorf("group requires o")
+	}
+	if config.group < 0 || (config.grepRegexp != nil && config.group > config.grepRegexp.NumSubexp()) {
+		return config, fmt.Errorf("invalid group %d", config.group)
+	}
+
 	if config.maxDepth < 0 {
 		return config, fmt.Errorf("max-depth should be positive")
 	}
@@ -284,31 +612,64 @@ func readConfig() (config, error) {
 	if config.maxRepoResults < 0 {
 		return config, fmt.Errorf("max-repo-results should be positive")
 	}
+	if config.resume && config.checkpointFile == "" {
+		return config, fmt.Errorf("resume requires checkpoint")
+	}
+	if config.repoTimeout < 0 {
+		return config, fmt.Errorf("repo-timeout should be positive")
+	}
 	if config.maxGrepResults < 0 {
 		return config, fmt.Errorf("max-grep-results should be positive")
 	}
+	if config.tarballThreshold < -1 {
+		return config, fmt.Errorf("invalid tarball-threshold %d", config.tarballThreshold)
+	}
 
-	if fsize != "" {
-		p := &sizePredicate{}
-		switch fsize[0] {
-		case '+':
-			p.op = 1
-		case '-':
-			p.op = -1
+	if largeFiles != "" {
+		if config.largeFiles, err = size.Parse(largeFiles); err != nil || config.largeFiles <= 0 {
+			return config, fmt.Errorf("invalid large-files %s", largeFiles)
 		}
-		offset := 0
-		if p.op != 0 {
-			offset = 1
+		config.ftype = typeFile // Implies file type.
+	}
+	if maxGrepSize != "" {
+		if config.maxGrepSize, err = size.Parse(maxGrepSize); err != nil {
+			return config, fmt.Errorf("invalid max-grep-size %s", maxGrepSize)
 		}
-		value, err := size.Parse(fsize[offset:])
+	}
+
+	for _, s := range fsize {
+		predicates, err := parseSizePredicates(s)
 		if err != nil {
-			return config, fmt.Errorf("invalid size %s", fsize)
+			return config, fmt.Errorf("invalid size %s", s)
 		}
-		p.value = value
-		config.size = p
+		config.sizes = append(config.sizes, predicates...)
 		config.ftype = typeFile // Implies file type.
 	}
 
+	for _, p := range perm {
+		mode, err := parseMode(p)
+		if err != nil {
+			return config, err
+		}
+		config.modes = append(config.modes, mode)
+	}
+
+	if mtime != "" {
+		if config.mtime, err = parseAgePredicate(mtime); err != nil {
+			return config, err
+		}
+	}
+	if author != "" {
+		if config.authorRegexp, err = regexp.Compile(author); err != nil {
+			return config, fmt.Errorf("invalid author pattern: %s", err)
+		}
+	}
+	if committer != "" {
+		if config.committerRegexp, err = regexp.Compile(committer); err != nil {
+			return config, fmt.Errorf("invalid committer pattern: %s", err)
+		}
+	}
+
 	if config.noMatches {
 		// Implies no limit on max overall results.
 		config.maxResults = 0
@@ -344,24 +705,51 @@ func run(ctx context.Context) error {
 		return fmt.Errorf("access token is required")
 	}
 
-	finder.gh = github.NewClient(oauth2.NewClient(ctx, oauth2.StaticTokenSource(
+	httpClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
-	)))
+	))
+	if finder.config.summary {
+		transport := &countingTransport{transport: httpClient.Transport}
+		httpClient.Transport = transport
+		finder.summary = newSummary(transport)
+	}
+	// Slow down as the rate limit budget drops and retry API calls
+	// that failed due to rate limiting or transient errors.
+	httpClient.Transport = gh.NewRetryTransport(gh.NewThrottleTransport(httpClient.Transport))
+	finder.gh = github.NewClient(httpClient)
+
+	if finder.config.checkpointFile != "" {
+		finder.checkpoint = &checkpoint{path: finder.config.checkpointFile}
+		if finder.config.resume {
+			if finder.checkpoint, err = readCheckpoint(finder.config.checkpointFile); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !finder.config.noCache {
+		if finder.cache, err = newTreeCache(); err != nil {
+			fmt.Fprintf(finder.stderr, "Tree cache is disabled: %s\n", err)
+		}
+	}
 
 	return finder.find(ctx)
 }
 
 func (f *finder) find(ctx context.Context) error {
-	repos, err := gh.NewRepoFinder(f.gh).Find(ctx, gh.RepoFilter{
-		Owner:        f.config.owner,
-		Repo:         f.config.repo,
-		RepoRegexp:   f.config.repoRegexp,
-		Archived:     f.config.archived,
-		NoPrivate:    f.config.noPrivate,
-		NoPublic:     f.config.noPublic,
-		NoFork:       f.config.noFork,
-		NoRepoRegexp: f.config.noRepoRegexp,
-	})
+	if err := f.walk(ctx); err != nil {
+		return err
+	}
+
+	if err := f.flush(); err != nil { // Print sorted results if any.
+		return err
+	}
+
+	return f.summary.write(f.stderr)
+}
+
+func (f *finder) walk(ctx context.Context) error {
+	repos, err := f.getRepos(ctx)
 	if err != nil {
 		return err
 	}
@@ -369,41 +757,93 @@ func (f *finder) find(ctx context.Context) error {
 	var (
 		branch, entryPath, basename string
 		level, matched, repoMatched int
-		repo, prevRepo              *github.Repository
+		prevRepo                    *github.Repository
+		term                        = searchTerm(f.config.grepRegexp) // The code search term.
+		searched                    map[string]bool                   // Paths of files preselected by the code search.
 	)
+	defer f.closeArchive()
+	matched = f.checkpoint.matches() // Account for matches of the resumed run.
+
+	if f.config.useSearch && f.config.grepRegexp != nil && term == "" {
+		fmt.Fprintln(f.stderr, "Code search is disabled: the grep pattern has no literal bounded by non-word characters")
+	}
+
 nextRepo:
-	for _, repo = range repos {
-		if prevRepo != nil && f.config.noMatches && repoMatched == 0 {
-			fmt.Fprintln(f.stdout, prevRepo.GetFullName())
+	for i, repo := range repos {
+		if prevRepo != nil && f.config.noMatches && repoMatched == 0 && !f.timedOut {
+			if err = f.print(&result{Repo: prevRepo.GetFullName()}); err != nil {
+				return err
+			}
 		}
+		if prevRepo != nil && !f.timedOut {
+			if err = f.checkpoint.complete(prevRepo.GetFullName(), repoMatched); err != nil {
+				return err
+			}
+		}
+		f.closeArchive() // Reset the per repository state.
+
+		// Skip repositories completed by the resumed run.
+		if f.checkpoint.completed(repo.GetFullName()) {
+			if !f.config.quiet {
+				fmt.Fprintf(f.stderr, "[%d/%d] %s: completed, skipping\n", i+1, len(repos), repo.GetFullName())
+			}
+			prevRepo = nil
+			continue
+		}
+		repoCtx := f.repoContext(ctx)
+
 		prevRepo = repo
 		repoMatched = 0 // Reset per repository counter.
+		f.summary.startRepo(repo.GetFullName())
 
 		// Check the number of overall matched entries.
 		if f.config.maxResults > 0 && matched >= f.config.maxResults {
 			return nil
 		}
 
+		if !f.config.quiet {
+			fmt.Fprintf(f.stderr, "[%d/%d] %s\n", i+1, len(repos), repo.GetFullName())
+		}
+
 		branch = f.config.branch
 		if branch == "" {
 			branch = repo.GetDefaultBranch()
 		}
 
-		tree, resp, err := f.gh.Git.GetTree(ctx, f.config.owner, repo.GetName(), branch, true)
+		entries, err := f.getEntries(repoCtx, repo, branch)
 		if err != nil {
-			if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusConflict {
-				// http.StatusConflict - Git Repository is empty.
-				continue
+			if f.repoTimedOut(ctx, repoCtx, repo) {
+				continue nextRepo
 			}
 			return err
 		}
+		if len(entries) == 0 {
+			continue
+		}
+
+		// The code search only indexes default branches.
+		searched = nil
+		if f.config.useSearch && term != "" && branch == repo.GetDefaultBranch() {
+			if paths, ok := f.searchPaths(repoCtx, repo, term); ok {
+				searched = paths
+			}
+		}
+
+		var nonEmpty map[string]bool
+		if f.config.empty {
+			nonEmpty = nonEmptyDirs(entries)
+		}
 
-		if tree.GetTruncated() {
-			fmt.Fprintf(f.stderr, "WARNING: results were truncated for %s", repo.GetFullName())
+		var attrs []gitattribute
+		if f.config.noVendored || f.config.noGenerated || f.config.largeFiles > 0 {
+			attrs, err = f.getGitattributes(repoCtx, repo, branch, entries)
+			if err != nil {
+				fmt.Fprintf(f.stderr, "%s: error reading .gitattributes: %s\n", repo.GetFullName(), err)
+			}
 		}
 
 	nextEntry:
-		for _, entry := range tree.Entries {
+		for _, entry := range entries {
 			// Check the number of overall matched entries.
 			if f.config.maxResults > 0 && matched >= f.config.maxResults {
 				return nil
@@ -414,7 +854,12 @@ nextRepo:
 			}
 
 			entryPath = entry.GetPath()
-			level = levels(entryPath)
+			relPath, ok := relativePath(entryPath, f.config.startPath)
+			if !ok {
+				continue
+			}
+			f.summary.addEntry()
+			level = levels(relPath)
 			if f.config.minDepth > 0 && level < f.config.minDepth {
 				continue
 			}
@@ -433,11 +878,30 @@ nextRepo:
 				}
 			}
 
-			// Check size.
-			if f.config.size != nil && !f.config.size.match(int64(entry.GetSize())) {
+			if f.config.empty && !isEmpty(entry, nonEmpty) {
+				continue nextEntry
+			}
+
+			// Check linguist rules.
+			if f.config.noVendored && isVendored(entryPath, attrs) {
+				continue nextEntry
+			}
+			if f.config.noGenerated && isGenerated(entryPath, attrs) {
+				continue nextEntry
+			}
+
+			// Check mode.
+			if len(f.config.modes) > 0 && !contains(f.config.modes, entry.GetMode()) {
 				continue nextEntry
 			}
 
+			// Check size.
+			for _, p := range f.config.sizes {
+				if !p.Match(int64(entry.GetSize())) {
+					continue nextEntry
+				}
+			}
+
 			// Check for path rejects first.
 			if len(f.config.noPathRegexp) > 0 && matchAny(entryPath, f.config.noPathRegexp) {
 				continue nextEntry
@@ -456,10 +920,63 @@ nextRepo:
 			if len(f.config.nameRegexp) > 0 && !matchAny(basename, f.config.nameRegexp) {
 				continue nextEntry
 			}
+			// Check the last commit touching the entry.
+			var lastCommit *github.RepositoryCommit
+			if f.config.mtime != nil || f.config.authorRegexp != nil || f.config.committerRegexp != nil {
+				lastCommit, err = f.getLastCommit(repoCtx, repo, branch, entry)
+				if err != nil {
+					if f.repoTimedOut(ctx, repoCtx, repo) {
+						continue nextRepo
+					}
+					return err
+				}
+				if lastCommit == nil {
+					continue nextEntry
+				}
+				// Check the age.
+				if f.config.mtime != nil && !f.config.mtime.match(time.Since(commitDate(lastCommit))) {
+					continue nextEntry
+				}
+				// Check the author and the committer.
+				if f.config.authorRegexp != nil &&
+					!matchCommitUser(f.config.authorRegexp, lastCommit.GetAuthor(), lastCommit.GetCommit().GetAuthor()) {
+					continue nextEntry
+				}
+				if f.config.committerRegexp != nil &&
+					!matchCommitUser(f.config.committerRegexp, lastCommit.GetCommitter(), lastCommit.GetCommit().GetCommitter()) {
+					continue nextEntry
+				}
+			}
+
+			// Check for large files and Git LFS pointers.
+			var lfs *resultLFS
+			if f.config.largeFiles > 0 && int64(entry.GetSize()) <= f.config.largeFiles {
+				lfs, err = f.getLFSPointer(repoCtx, repo, entry, attrs)
+				if err != nil {
+					if f.repoTimedOut(ctx, repoCtx, repo) {
+						continue nextRepo
+					}
+					return err
+				}
+				if lfs == nil {
+					continue nextEntry
+				}
+			}
+
+			// Check if the file is too large to grep.
+			if (f.config.grepRegexp != nil || f.config.noGrepRegexp != nil) && entry.GetType() == "blob" &&
+				f.config.maxGrepSize > 0 && int64(entry.GetSize()) > f.config.maxGrepSize {
+				fmt.Fprintf(f.stderr, "%s: %s: skipped grepping, size %d exceeds max-grep-size\n", repo.GetFullName(), entryPath, entry.GetSize())
+				continue nextEntry
+			}
+
 			// Check if we need to reject based on the contents of the file.
 			if f.config.noGrepRegexp != nil && entry.GetType() == "blob" {
-				results, err := f.grepContents(ctx, repo, branch, entry, 1)
+				results, err := f.grepContents(repoCtx, repo, branch, entry, 1)
 				if err != nil {
+					if f.repoTimedOut(ctx, repoCtx, repo) {
+						continue nextRepo
+					}
 					return err
 				}
 				if len(results.matches) > 0 {
@@ -468,19 +985,69 @@ nextRepo:
 			}
 
 			if f.config.grepRegexp != nil && entry.GetType() == "blob" {
-				results, err := f.grepContents(ctx, repo, branch, entry, f.config.maxGrepResults)
+				// Skip files the code search didn't find unless they're too large to be indexed.
+				if searched != nil && !searched[entryPath] && entry.GetSize() <= maxSearchIndexedSize {
+					continue nextEntry
+				}
+
+				results, err := f.grepContents(repoCtx, repo, branch, entry, f.config.maxGrepResults)
 				if err != nil {
+					if f.repoTimedOut(ctx, repoCtx, repo) {
+						continue nextRepo
+					}
 					return err
 				}
 
 				if len(results.matches) > 0 {
 					matched++
 					repoMatched++
+					f.summary.addMatch()
+				}
+
+				if f.config.exec != nil && !f.config.noMatches && len(results.matches) > 0 {
+					if err = f.exec(repoCtx, repo, branch, entry); err != nil {
+						if f.repoTimedOut(ctx, repoCtx, repo) {
+							continue nextRepo
+						}
+						return err
+					}
+					continue nextEntry
 				}
 
-				if !f.config.noMatches {
+				if !f.config.noMatches && len(results.matches) > 0 {
+					var commit *resultCommit
+					if f.config.sort == sortMtime {
+						commit, err = f.resultCommit(repoCtx, repo, branch, entry, lastCommit)
+						if err != nil {
+							if f.repoTimedOut(ctx, repoCtx, repo) {
+								continue nextRepo
+							}
+							return err
+						}
+					}
 					for _, match := range results.matches {
-						fmt.Fprintln(f.stdout, repo.GetFullName(), entry.GetPath(), match.lineno, match.line)
+						lines := []string{match.line}
+						if f.config.onlyMatching && !results.isBinary {
+							lines = onlyMatching(match.line, f.config.grepRegexp, f.config.group)
+						}
+						for _, line := range lines {
+							err = f.print(&result{
+								Repo:   repo.GetFullName(),
+								Path:   entry.GetPath(),
+								Type:   entryType(entry),
+								Mode:   entry.GetMode(),
+								Size:   entry.GetSize(),
+								SHA:    entry.GetSHA(),
+								URL:    entryURL(repo, branch, entry, match.lineno),
+								LineNo: match.lineno,
+								Line:   line,
+								Binary: results.isBinary,
+								Commit: commit,
+							})
+							if err != nil {
+								return err
+							}
+						}
 					}
 				}
 				continue nextEntry
@@ -488,31 +1055,91 @@ nextRepo:
 
 			matched++
 			repoMatched++
-			if !f.config.noMatches {
-				if !f.config.listDetails {
-					fmt.Fprintln(f.stdout, repo.GetFullName(), entry.GetPath())
-					continue nextEntry
+			f.summary.addMatch()
+			if f.config.exec != nil && !f.config.noMatches {
+				if err = f.exec(repoCtx, repo, branch, entry); err != nil {
+					if f.repoTimedOut(ctx, repoCtx, repo) {
+						continue nextRepo
+					}
+					return err
 				}
+				continue nextEntry
+			}
 
-				commit, err := f.getLastCommit(ctx, repo, branch, entry)
-				if err != nil {
+			if !f.config.noMatches {
+				res := &result{
+					Repo: repo.GetFullName(),
+					Path: entry.GetPath(),
+					Type: entryType(entry),
+					Mode: entry.GetMode(),
+					Size: entry.GetSize(),
+					SHA:  entry.GetSHA(),
+					URL:  entryURL(repo, branch, entry, 0),
+					LFS:  lfs,
+				}
+				if f.config.listDetails || f.config.sort == sortMtime {
+					res.Commit, err = f.resultCommit(repoCtx, repo, branch, entry, lastCommit)
+					if err != nil {
+						if f.repoTimedOut(ctx, repoCtx, repo) {
+							continue nextRepo
+						}
+						return err
+					}
+				}
+				if err = f.print(res); err != nil {
 					return err
 				}
-				fmt.Fprintln(f.stdout, repo.GetFullName(), entryType(entry),
-					commit.Author.GetLogin(), entry.GetSize(),
-					commit.Commit.Author.GetDate().Format("Jan 2 15:04:05 2006"),
-					entry.GetPath(),
-				)
 			}
 		}
 	}
-	if prevRepo != nil && f.config.noMatches && repoMatched == 0 {
-		fmt.Fprintln(f.stdout, prevRepo.GetFullName())
+	if prevRepo != nil && f.config.noMatches && repoMatched == 0 && !f.timedOut {
+		if err = f.print(&result{Repo: prevRepo.GetFullName()}); err != nil {
+			return err
+		}
+	}
+	if prevRepo != nil && !f.timedOut {
+		return f.checkpoint.complete(prevRepo.GetFullName(), repoMatched)
 	}
 
 	return nil
 }
 
+// getRepos gets repositories listed in the repo file
+// or finds repositories matching the filter.
+func (f *finder) getRepos(ctx context.Context) ([]*github.Repository, error) {
+	finder := gh.NewRepoFinder(f.gh)
+	if len(f.config.repoNames) > 0 {
+		repos, err := finder.Get(ctx, f.config.repoNames)
+		if err != nil {
+			return nil, err
+		}
+
+		return gh.Filter(repos, gh.RepoFilter{
+			RepoRegexp:   f.config.repoRegexp,
+			Archived:     f.config.archived,
+			NoPrivate:    f.config.noPrivate,
+			NoPublic:     f.config.noPublic,
+			NoFork:       f.config.noFork,
+			NoRepoRegexp: f.config.noRepoRegexp,
+			Topics:       f.config.topics,
+			Languages:    f.config.languages,
+		}), nil
+	}
+
+	return finder.Find(ctx, gh.RepoFilter{
+		Owner:        f.config.owner,
+		Repo:         f.config.repo,
+		RepoRegexp:   f.config.repoRegexp,
+		Archived:     f.config.archived,
+		NoPrivate:    f.config.noPrivate,
+		NoPublic:     f.config.noPublic,
+		NoFork:       f.config.noFork,
+		NoRepoRegexp: f.config.noRepoRegexp,
+		Topics:       f.config.topics,
+		Languages:    f.config.languages,
+	})
+}
+
 func entryType(e *github.TreeEntry) string {
 	if e == nil {
 		return ""
@@ -528,6 +1155,67 @@ func entryType(e *github.TreeEntry) string {
 	}
 }
 
+// repoContext returns the context for the repository limited by the repo-timeout.
+// The context is canceled when the per repository state is reset.
+func (f *finder) repoContext(ctx context.Context) context.Context {
+	if f.config.repoTimeout <= 0 {
+		return ctx
+	}
+
+	ctx, f.cancelRepo = context.WithTimeout(ctx, f.config.repoTimeout)
+	return ctx
+}
+
+// repoTimedOut checks if the repository exceeded the repo-timeout rather than
+// the whole run was canceled and reports the repository as skipped.
+func (f *finder) repoTimedOut(ctx, repoCtx context.Context, repo *github.Repository) bool {
+	if ctx.Err() != nil || repoCtx.Err() != context.DeadlineExceeded {
+		return false
+	}
+
+	f.timedOut = true
+	fmt.Fprintf(f.stderr, "%s: skipped, repo-timeout %s exceeded\n", repo.GetFullName(), f.config.repoTimeout)
+	return true
+}
+
+// resultCommit returns the last commit touching the entry.
+// The commit is fetched unless it's been already fetched.
+func (f *finder) resultCommit(ctx context.Context, repo *github.Repository, branch string, entry *github.TreeEntry, commit *github.RepositoryCommit) (*resultCommit, error) {
+	if commit == nil {
+		var err error
+		commit, err = f.getLastCommit(ctx, repo, branch, entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if commit == nil {
+		return nil, nil
+	}
+
+	return &resultCommit{
+		SHA:    commit.GetSHA(),
+		Author: commit.GetAuthor().GetLogin(),
+		Date:   commitDate(commit),
+	}, nil
+}
+
+// commitDate returns the author date of the commit.
+func commitDate(commit *github.RepositoryCommit) time.Time {
+	return commit.GetCommit().GetAuthor().GetDate()
+}
+
+// matchCommitUser checks if the pattern matches the GitHub login,
+// the name or the email of the commit author or committer.
+func matchCommitUser(re *regexp.Regexp, user *github.User, signature *github.CommitAuthor) bool {
+	for _, s := range []string{user.GetLogin(), signature.GetName(), signature.GetEmail()} {
+		if s != "" && re.MatchString(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (f *finder) getLastCommit(ctx context.Context, repo *github.Repository, branch string, entry *github.TreeEntry) (*github.RepositoryCommit, error) {
 	opts := &github.CommitsListOptions{
 		SHA:  branch,
@@ -537,7 +1225,7 @@ func (f *finder) getLastCommit(ctx context.Context, repo *github.Repository, bra
 			PerPage: 1,
 		},
 	}
-	commits, resp, err := f.gh.Repositories.ListCommits(ctx, f.config.owner, repo.GetName(), opts)
+	commits, resp, err := f.gh.Repositories.ListCommits(ctx, repo.GetOwner().GetLogin(), repo.GetName(), opts)
 	if err != nil {
 		return nil, err
 	}
@@ -555,20 +1243,42 @@ func (f *finder) grepContents(ctx context.Context, repo *github.Repository, bran
 		return nil, nil // There is nothing to do.
 	}
 
-	opts := &github.RepositoryContentGetOptions{Ref: branch}
-	contents, err := f.gh.Repositories.DownloadContents(ctx, f.config.owner, repo.GetName(), entry.GetPath(), opts)
+	contents, err := f.openContents(ctx, repo, branch, entry)
 	if err != nil {
 		return nil, err
 	}
 	defer contents.Close()
 
-	return grep(contents, f.config.grepRegexp, limit)
+	return grep(contents, f.config.grepRegexp, limit, f.config.binary)
+}
+
+// relativePath returns the path relative to the start path
+// if the path is within the start path.
+func relativePath(path, start string) (string, bool) {
+	if start == "" {
+		return path, true
+	}
+	if !strings.HasPrefix(path, start+"/") {
+		return "", false
+	}
+
+	return path[len(start)+1:], true
 }
 
 func levels(path string) int {
 	return len(path) - len(strings.ReplaceAll(path, "/", "")) + 1
 }
 
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
 func matchAny(s string, regexes []*regexp.Regexp) bool {
 	for _, regex := range regexes {
 		if regex.MatchString(s) {
@@ -1,8 +1,12 @@
 package main
 
 import (
-	"fmt"
+	"reflect"
+	"regexp"
 	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/pmatseykanets/gh-tools/size"
 )
 
 func TestLevels(t *testing.T) {
@@ -29,32 +33,96 @@ func TestLevels(t *testing.T) {
 	}
 }
 
-func TestSizePredicateMatch(t *testing.T) {
+func TestParseSizePredicates(t *testing.T) {
 	tests := []struct {
-		op    int
-		value int64
-		size  int64
-		is    bool
+		s    string
+		want []*size.Predicate
+		err  bool
 	}{
-		{-1, 1024, 1023, true},
-		{-1, 1024, 1024, true},
-		{-1, 1023, 1024, false},
-		{0, 1024, 1024, true},
-		{0, 1024, 1023, false},
-		{0, 1024, 1025, false},
-		{1, 1024, 1024, true},
-		{1, 1024, 1025, true},
-		{1, 1024, 1023, false},
+		{s: "10", want: []*size.Predicate{{Op: 0, Value: 10}}},
+		{s: "+1K", want: []*size.Predicate{{Op: 1, Value: 1000}}},
+		{s: "-1Mi", want: []*size.Predicate{{Op: -1, Value: 1024 * 1024}}},
+		{s: "1K..2K", want: []*size.Predicate{{Op: 1, Value: 1000}, {Op: -1, Value: 2000}}},
+		{s: "2K..1K", err: true},
+		{s: "1K..", err: true},
+		{s: "+", err: true},
+		{s: "foo", err: true},
 	}
 
 	for _, tt := range tests {
 		tt := tt
-		t.Run(fmt.Sprint(tt.op, tt.value, tt.size), func(t *testing.T) {
+		t.Run(tt.s, func(t *testing.T) {
 			t.Parallel()
-			p := &sizePredicate{op: tt.op, value: tt.value}
-			if want, got := tt.is, p.match(tt.size); want != got {
+
+			got, err := parseSizePredicates(tt.s)
+			if tt.err {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := tt.want; !reflect.DeepEqual(want, got) {
 				t.Errorf("Expected %v got %v", want, got)
 			}
 		})
 	}
 }
+
+func TestRelativePath(t *testing.T) {
+	tests := []struct {
+		path  string
+		start string
+		rel   string
+		ok    bool
+	}{
+		{"a/b", "", "a/b", true},
+		{".github/workflows/ci.yml", ".github/workflows", "ci.yml", true},
+		{".github/workflows/a/b.yml", ".github/workflows", "a/b.yml", true},
+		{".github/workflows", ".github/workflows", "", false},
+		{".github/workflows-old/ci.yml", ".github/workflows", "", false},
+		{"README", ".github", "", false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			rel, ok := relativePath(tt.path, tt.start)
+			if want, got := tt.ok, ok; want != got {
+				t.Errorf("Expected %v got %v", want, got)
+			}
+			if want, got := tt.rel, rel; want != got {
+				t.Errorf("Expected %q got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestMatchCommitUser(t *testing.T) {
+	user := &github.User{Login: github.String("jdoe")}
+	signature := &github.CommitAuthor{Name: github.String("Jane Doe"), Email: github.String("jane@contractor.example")}
+
+	tests := []struct {
+		pattern   string
+		user      *github.User
+		signature *github.CommitAuthor
+		want      bool
+	}{
+		{"^jdoe$", user, signature, true},
+		{"Jane", user, signature, true},
+		{"@contractor\\.example$", user, signature, true},
+		{"^john", user, signature, false},
+		{"jdoe", nil, signature, false},
+		{".*", nil, nil, false},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.want, matchCommitUser(regexp.MustCompile(tt.pattern), tt.user, tt.signature); want != got {
+			t.Errorf("%s: expected %v got %v", tt.pattern, want, got)
+		}
+	}
+}
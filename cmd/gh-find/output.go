@@ -0,0 +1,365 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// csvHeader is the header row of the csv format.
+var csvHeader = []string{
+	"repo", "path", "type", "mode", "size", "sha", "url", "line_number", "line", "binary",
+	"commit_sha", "commit_author", "commit_date", "lfs_oid", "lfs_size",
+}
+
+const (
+	sortPath  = "path"
+	sortSize  = "size"
+	sortRepo  = "repo"
+	sortMtime = "mtime"
+)
+
+// result represents a matched entry, a grep match or a repository with no matches.
+type result struct {
+	Repo   string        `json:"repo"`
+	Path   string        `json:"path,omitempty"`
+	Type   string        `json:"type,omitempty"` // f - file, d - directory.
+	Mode   string        `json:"mode,omitempty"`
+	Size   int           `json:"size,omitempty"`
+	SHA    string        `json:"sha,omitempty"`
+	URL    string        `json:"url,omitempty"`
+	LineNo int64         `json:"line_number,omitempty"`
+	Line   string        `json:"line,omitempty"`
+	Binary bool          `json:"binary,omitempty"` // A binary file matches.
+	LFS    *resultLFS    `json:"lfs,omitempty"`    // A Git LFS pointer.
+	Commit *resultCommit `json:"commit,omitempty"`
+}
+
+// jsonResult is the JSON representation of the result.
+// Unlike other fields the size of empty files is included.
+type jsonResult struct {
+	Repo   string        `json:"repo"`
+	Path   string        `json:"path,omitempty"`
+	Type   string        `json:"type,omitempty"`
+	Mode   string        `json:"mode,omitempty"`
+	Size   *int          `json:"size,omitempty"` // Set for files only.
+	SHA    string        `json:"sha,omitempty"`
+	URL    string        `json:"url,omitempty"`
+	LineNo int64         `json:"line_number,omitempty"`
+	Line   string        `json:"line,omitempty"`
+	Binary bool          `json:"binary,omitempty"`
+	LFS    *resultLFS    `json:"lfs,omitempty"`
+	Commit *resultCommit `json:"commit,omitempty"`
+}
+
+// newJSONResult converts the result to its JSON representation.
+func newJSONResult(r *result) *jsonResult {
+	jr := &jsonResult{
+		Repo:   r.Repo,
+		Path:   r.Path,
+		Type:   r.Type,
+		Mode:   r.Mode,
+		SHA:    r.SHA,
+		URL:    r.URL,
+		LineNo: r.LineNo,
+		Line:   r.Line,
+		Binary: r.Binary,
+		LFS:    r.LFS,
+		Commit: r.Commit,
+	}
+	if r.Type == typeFile {
+		size := r.Size
+		jr.Size = &size
+	}
+
+	return jr
+}
+
+// resultCommit represents the last commit touching the entry.
+type resultCommit struct {
+	SHA    string    `json:"sha"`
+	Author string    `json:"author"`
+	Date   time.Time `json:"date"`
+}
+
+// print writes the result to stdout in the configured format.
+// Results are buffered until flushed if they need to be sorted or grouped.
+func (f *finder) print(r *result) error {
+	if f.config.sort != "" || f.config.duplicates {
+		f.results = append(f.results, r)
+		return nil
+	}
+	if f.config.groupByRepo {
+		return f.printGrouped(r)
+	}
+
+	return f.write(r)
+}
+
+// flush sorts and writes buffered results.
+func (f *finder) flush() error {
+	if f.config.duplicates {
+		return f.flushDuplicates()
+	}
+
+	sortResults(f.results, f.config.sort, f.config.reverse)
+	if f.config.groupByRepo {
+		return f.flushGroups()
+	}
+	for _, r := range f.results {
+		if err := f.write(r); err != nil {
+			return err
+		}
+	}
+	f.results = nil
+
+	return nil
+}
+
+// flushDuplicates writes groups of identical entries.
+// In the text format groups are separated by an empty line.
+func (f *finder) flushDuplicates() error {
+	separate := f.config.format == formatText && !f.config.print0 && f.config.template == nil
+	for i, group := range duplicateGroups(f.results) {
+		if i > 0 && separate {
+			if _, err := fmt.Fprintln(f.stdout); err != nil {
+				return err
+			}
+		}
+		sortResults(group, f.config.sort, f.config.reverse)
+		for _, r := range group {
+			if err := f.write(r); err != nil {
+				return err
+			}
+		}
+	}
+	f.results = nil
+
+	return nil
+}
+
+// sortResults sorts results by the key keeping the relative order
+// of results with equal keys (e.g. grep matches within a file).
+func sortResults(results []*result, key string, reverse bool) {
+	less := func(a, b *result) bool {
+		switch key {
+		case sortSize:
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+		case sortMtime:
+			if ad, bd := a.commitDate(), b.commitDate(); !ad.Equal(bd) {
+				return ad.Before(bd)
+			}
+		case sortPath:
+			if a.Path != b.Path {
+				return a.Path < b.Path
+			}
+		}
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		return a.Path < b.Path
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if reverse {
+			return less(results[j], results[i])
+		}
+		return less(results[i], results[j])
+	})
+}
+
+// commitDate returns the date of the last commit if known.
+func (r *result) commitDate() time.Time {
+	if r.Commit == nil {
+		return time.Time{}
+	}
+
+	return r.Commit.Date
+}
+
+// write writes the result to stdout in the configured format.
+func (f *finder) write(r *result) error {
+	switch f.config.format {
+	case formatJSON:
+		encoder := json.NewEncoder(f.stdout)
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(newJSONResult(r))
+	case formatCSV:
+		return f.writeCSV(r)
+	default:
+		if f.config.colorize {
+			r = f.colorize(r)
+		}
+		if f.config.template != nil {
+			return f.writeTemplate(r)
+		}
+		fields := formatResult(r, f.config.listDetails, f.config.url)
+		if f.config.print0 {
+			_, err := fmt.Fprint(f.stdout, joinFields(fields, "\t"), "\x00")
+			return err
+		}
+		_, err := fmt.Fprintln(f.stdout, fields...)
+		return err
+	}
+}
+
+// writeCSV writes the result as a csv record
+// preceded by the header row if it's the first one.
+func (f *finder) writeCSV(r *result) error {
+	if f.csv == nil {
+		f.csv = csv.NewWriter(f.stdout)
+		if err := f.csv.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+
+	if err := f.csv.Write(csvRecord(r)); err != nil {
+		return err
+	}
+	f.csv.Flush()
+
+	return f.csv.Error()
+}
+
+// csvRecord returns the fields of the result in the order of the csv header.
+func csvRecord(r *result) []string {
+	record := make([]string, len(csvHeader))
+	record[0] = r.Repo
+	if r.Path == "" { // A repository with no matches.
+		return record
+	}
+
+	record[1] = r.Path
+	record[2] = r.Type
+	record[3] = r.Mode
+	record[4] = strconv.Itoa(r.Size)
+	record[5] = r.SHA
+	record[6] = r.URL
+	if r.LineNo > 0 {
+		record[7] = strconv.FormatInt(r.LineNo, 10)
+	}
+	record[8] = r.Line
+	record[9] = strconv.FormatBool(r.Binary)
+	if r.Commit != nil {
+		record[10] = r.Commit.SHA
+		record[11] = r.Commit.Author
+		record[12] = r.Commit.Date.Format(time.RFC3339)
+	}
+	if r.LFS != nil {
+		record[13] = r.LFS.OID
+		record[14] = strconv.FormatInt(r.LFS.Size, 10)
+	}
+
+	return record
+}
+
+// formatResult returns the fields of the result in the text format.
+// The repository and the path are replaced with the web URL if urls is set.
+func formatResult(r *result, details, urls bool) []interface{} {
+	if r.Path == "" { // A repository with no matches.
+		return []interface{}{r.Repo}
+	}
+
+	location := []interface{}{r.Repo, r.Path}
+	if urls && r.URL != "" {
+		location = []interface{}{r.URL}
+	}
+
+	switch {
+	case r.Binary: // A binary file grep match.
+		return append(location, "binary file matches")
+	case r.LineNo > 0 && urls: // A grep match. The URL points to the line.
+		return append(location, r.Line)
+	case r.LineNo > 0: // A grep match.
+		return append(location, r.LineNo, r.Line)
+	case details:
+		var author, date string
+		if r.Commit != nil {
+			author = r.Commit.Author
+			date = r.Commit.Date.Format("Jan 2 15:04:05 2006")
+		}
+		if len(location) == 1 {
+			return []interface{}{r.Type, author, r.Size, date, r.URL}
+		}
+		return []interface{}{r.Repo, r.Type, author, r.Size, date, r.Path}
+	case r.LFS != nil: // A Git LFS pointer.
+		return append(location, "lfs", r.LFS.Size)
+	default:
+		return location
+	}
+}
+
+// joinFields joins string representations of fields with the separator.
+func joinFields(fields []interface{}, sep string) string {
+	s := make([]string, len(fields))
+	for i, field := range fields {
+		s[i] = fmt.Sprint(field)
+	}
+
+	return strings.Join(s, sep)
+}
+
+// parseTemplate parses the output template expanding \t, \n and \0 escape sequences.
+func parseTemplate(s string) (*template.Template, error) {
+	s = strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\0`, "\x00").Replace(s)
+	return template.New("format").Option("missingkey=error").Parse(s)
+}
+
+// writeTemplate writes the result formatted with the template.
+func (f *finder) writeTemplate(r *result) error {
+	var b strings.Builder
+	if err := f.config.template.Execute(&b, r); err != nil {
+		return err
+	}
+	if f.config.print0 {
+		b.WriteString("\x00")
+	} else {
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(f.stdout, b.String())
+	return err
+}
+
+// entryURL returns the web URL of the entry at the ref
+// with the line anchor if the line number is given.
+func entryURL(repo *github.Repository, ref string, entry *github.TreeEntry, lineno int64) string {
+	kind := "blob"
+	if entry.GetType() == "tree" {
+		kind = "tree"
+	}
+
+	u := fmt.Sprintf("%s/%s/%s/%s", repo.GetHTMLURL(), kind, escapePath(ref), escapePath(entry.GetPath()))
+	if lineno > 0 {
+		u += fmt.Sprintf("#L%d", lineno)
+	}
+
+	return u
+}
+
+// escapePath escapes segments of the slash separated path.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
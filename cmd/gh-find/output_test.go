@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func TestPrint(t *testing.T) {
+	date := time.Date(2021, 3, 5, 10, 11, 12, 0, time.UTC)
+	tests := []struct {
+		format  string
+		details bool
+		print0  bool
+		urls    bool
+		result  *result
+		out     string
+	}{
+		{formatText, false, false, false, &result{Repo: "foo/bar"}, "foo/bar\n"},
+		{formatText, false, false, false, &result{Repo: "foo/bar", Path: "a/b", Type: "f", Size: 10}, "foo/bar a/b\n"},
+		{formatText, false, false, false, &result{Repo: "foo/bar", Path: "a/b", Type: "f", LineNo: 3, Line: "baz qux"}, "foo/bar a/b 3 baz qux\n"},
+		{
+			formatText, true, false, false,
+			&result{Repo: "foo/bar", Path: "a/b", Type: "f", Size: 10, Commit: &resultCommit{SHA: "abc", Author: "jane", Date: date}},
+			"foo/bar f jane 10 Mar 5 10:11:12 2021 a/b\n",
+		},
+		{formatText, false, false, false, &result{Repo: "foo/bar", Path: "a/b", Type: "f", Binary: true}, "foo/bar a/b binary file matches\n"},
+		{formatText, false, true, false, &result{Repo: "foo/bar", Path: "a b/c", Type: "f"}, "foo/bar\ta b/c\x00"},
+		{formatText, false, true, false, &result{Repo: "foo/bar", Path: "a b", Type: "f", LineNo: 3, Line: "baz qux"}, "foo/bar\ta b\t3\tbaz qux\x00"},
+		{formatText, false, false, true, &result{Repo: "foo/bar"}, "foo/bar\n"},
+		{formatText, false, false, true, &result{Repo: "foo/bar", Path: "a/b", URL: "https://github.com/foo/bar/blob/main/a/b"}, "https://github.com/foo/bar/blob/main/a/b\n"},
+		{formatText, false, false, true, &result{Repo: "foo/bar", Path: "a/b", URL: "https://github.com/foo/bar/blob/main/a/b#L3", LineNo: 3, Line: "baz qux"}, "https://github.com/foo/bar/blob/main/a/b#L3 baz qux\n"},
+		{formatJSON, false, false, false, &result{Repo: "foo/bar"}, `{"repo":"foo/bar"}` + "\n"},
+		{
+			formatJSON, false, false, false,
+			&result{Repo: "foo/bar", Path: "a/b", Type: "f", Size: 10, LineNo: 3, Line: "<baz>"},
+			`{"repo":"foo/bar","path":"a/b","type":"f","size":10,"line_number":3,"line":"<baz>"}` + "\n",
+		},
+		{
+			formatJSON, false, false, false,
+			&result{Repo: "foo/bar", Path: "a/empty", Type: "f"},
+			`{"repo":"foo/bar","path":"a/empty","type":"f","size":0}` + "\n",
+		},
+		{
+			formatJSON, true, false, false,
+			&result{Repo: "foo/bar", Path: "a", Type: "d", Commit: &resultCommit{SHA: "abc", Author: "jane", Date: date}},
+			`{"repo":"foo/bar","path":"a","type":"d","commit":{"sha":"abc","author":"jane","date":"2021-03-05T10:11:12Z"}}` + "\n",
+		},
+		{
+			formatCSV, false, false, false,
+			&result{Repo: "foo/bar"},
+			"repo,path,type,mode,size,sha,url,line_number,line,binary,commit_sha,commit_author,commit_date,lfs_oid,lfs_size\n" +
+				"foo/bar,,,,,,,,,,,,,,\n",
+		},
+		{
+			formatCSV, true, false, false,
+			&result{Repo: "foo/bar", Path: "a/b", Type: "f", Mode: "100644", Size: 10, SHA: "def", LineNo: 3, Line: `baz, "qux"`, Commit: &resultCommit{SHA: "abc", Author: "jane", Date: date}},
+			"repo,path,type,mode,size,sha,url,line_number,line,binary,commit_sha,commit_author,commit_date,lfs_oid,lfs_size\n" +
+				`foo/bar,a/b,f,100644,10,def,,3,"baz, ""qux""",false,abc,jane,2021-03-05T10:11:12Z,,` + "\n",
+		},
+	}
+
+	for i, tt := range tests {
+		tt := tt
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+
+			out := nopCloser{&bytes.Buffer{}}
+			f := &finder{config: config{format: tt.format, listDetails: tt.details, print0: tt.print0, url: tt.urls}, stdout: out}
+			if err := f.print(tt.result); err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tt.out, out.String(); want != got {
+				t.Errorf("Expected %q got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestSortResults(t *testing.T) {
+	day := func(d int) *resultCommit {
+		return &resultCommit{Date: time.Date(2021, 3, d, 0, 0, 0, 0, time.UTC)}
+	}
+	results := func() []*result {
+		return []*result{
+			{Repo: "foo/b", Path: "z", Size: 1, Commit: day(3)},
+			{Repo: "foo/a", Path: "y", Size: 3, LineNo: 1, Commit: day(1)},
+			{Repo: "foo/a", Path: "y", Size: 3, LineNo: 2, Commit: day(1)},
+			{Repo: "foo/c", Path: "x", Size: 2},
+		}
+	}
+	paths := func(results []*result) string {
+		var s string
+		for _, r := range results {
+			s += fmt.Sprint(r.Repo, ":", r.Path, ":", r.LineNo, " ")
+		}
+		return s
+	}
+
+	tests := []struct {
+		key     string
+		reverse bool
+		out     string
+	}{
+		{sortPath, false, "foo/c:x:0 foo/a:y:1 foo/a:y:2 foo/b:z:0 "},
+		{sortRepo, false, "foo/a:y:1 foo/a:y:2 foo/b:z:0 foo/c:x:0 "},
+		{sortSize, false, "foo/b:z:0 foo/c:x:0 foo/a:y:1 foo/a:y:2 "},
+		{sortSize, true, "foo/a:y:1 foo/a:y:2 foo/c:x:0 foo/b:z:0 "},
+		{sortMtime, false, "foo/c:x:0 foo/a:y:1 foo/a:y:2 foo/b:z:0 "},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(fmt.Sprint(tt.key, tt.reverse), func(t *testing.T) {
+			t.Parallel()
+
+			in := results()
+			sortResults(in, tt.key, tt.reverse)
+			if want, got := tt.out, paths(in); want != got {
+				t.Errorf("Expected %q got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestEntryURL(t *testing.T) {
+	repo := &github.Repository{HTMLURL: github.String("https://github.com/foo/bar")}
+	tests := []struct {
+		ref    string
+		entry  *github.TreeEntry
+		lineno int64
+		url    string
+	}{
+		{"main", &github.TreeEntry{Path: github.String("go.mod"), Type: github.String("blob")}, 0, "https://github.com/foo/bar/blob/main/go.mod"},
+		{"main", &github.TreeEntry{Path: github.String("cmd/a b.go"), Type: github.String("blob")}, 12, "https://github.com/foo/bar/blob/main/cmd/a%20b.go#L12"},
+		{"release/1.0", &github.TreeEntry{Path: github.String("docs"), Type: github.String("tree")}, 0, "https://github.com/foo/bar/tree/release/1.0/docs"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.url, func(t *testing.T) {
+			t.Parallel()
+
+			if want, got := tt.url, entryURL(repo, tt.ref, tt.entry, tt.lineno); want != got {
+				t.Errorf("Expected %q got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	tmpl, err := parseTemplate(`{{.Repo}}\t{{.Path}}\t{{.Size}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, print0 := range []bool{false, true} {
+		out := nopCloser{&bytes.Buffer{}}
+		f := &finder{config: config{template: tmpl, print0: print0}, stdout: out}
+		if err = f.print(&result{Repo: "foo/bar", Path: "a b", Size: 10}); err != nil {
+			t.Fatal(err)
+		}
+
+		want := "foo/bar\ta b\t10\n"
+		if print0 {
+			want = "foo/bar\ta b\t10\x00"
+		}
+		if got := out.String(); want != got {
+			t.Errorf("Expected %q got %q", want, got)
+		}
+	}
+
+	if _, err = parseTemplate("{{.Repo"); err == nil {
+		t.Errorf("Expected an error")
+	}
+}
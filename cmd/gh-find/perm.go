@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Git tree entry modes.
+var entryModes = map[string]string{
+	"regular":    "100644",
+	"executable": "100755",
+	"symlink":    "120000",
+	"submodule":  "160000",
+	"directory":  "040000",
+}
+
+// parseMode parses the entry mode given by name or as an octal number.
+func parseMode(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if mode, ok := entryModes[s]; ok {
+		return mode, nil
+	}
+	for _, mode := range entryModes {
+		if s == mode {
+			return mode, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid perm %s", s)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		in   string
+		mode string
+		err  bool
+	}{
+		{"executable", "100755", false},
+		{"Symlink", "120000", false},
+		{"regular", "100644", false},
+		{"submodule", "160000", false},
+		{"100755", "100755", false},
+		{"040000", "040000", false},
+		{"755", "", true},
+		{"foo", "", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			mode, err := parseMode(tt.in)
+			if tt.err {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tt.mode, mode; want != got {
+				t.Errorf("Expected %s got %s", want, got)
+			}
+		})
+	}
+}
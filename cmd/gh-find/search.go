@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/go-github/v32/github"
+)
+
+const (
+	maxSearchResults     = 1000       // The maximum number of code search results GitHub returns.
+	maxSearchIndexedSize = 384 * 1024 // Files larger than that are not indexed by code search.
+)
+
+// searchTerm returns the longest literal string required by the pattern
+// to be used as a code search term. The code search matches whole tokens
+// so the literal has to be bounded by non-word characters, anchors or \b
+// in the pattern. Returns an empty string if there is none.
+func searchTerm(re *regexp.Regexp) string {
+	if re == nil {
+		return ""
+	}
+
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return ""
+	}
+
+	t := literal(parsed.Simplify())
+	if !t.left || !t.right {
+		return ""
+	}
+
+	return strings.TrimSpace(t.s)
+}
+
+// term is a literal string required by an expression.
+type term struct {
+	s           string
+	left, right bool // Bounded by a non-word character on the left/right.
+	first, last bool // At the start/end of the expression.
+}
+
+// literal returns the longest literal string required by the expression
+// that is or can be bounded by non-word characters.
+func literal(re *syntax.Regexp) term {
+	switch re.Op {
+	case syntax.OpLiteral:
+		s := string(re.Rune)
+		if re.Flags&syntax.FoldCase != 0 {
+			s = strings.ToLower(s)
+		}
+		return term{
+			s:     s,
+			left:  !isWordRune(re.Rune[0]),
+			right: !isWordRune(re.Rune[len(re.Rune)-1]),
+			first: true,
+			last:  true,
+		}
+	case syntax.OpCapture, syntax.OpPlus:
+		return literal(re.Sub[0])
+	case syntax.OpConcat:
+		var longest term
+		for i, sub := range re.Sub {
+			t := literal(sub)
+			if t.s == "" {
+				continue
+			}
+			if t.first && i > 0 && endsNonWord(re.Sub[i-1]) {
+				t.left = true
+			}
+			if t.last && i < len(re.Sub)-1 && startsNonWord(re.Sub[i+1]) {
+				t.right = true
+			}
+			t.first = t.first && i == 0
+			t.last = t.last && i == len(re.Sub)-1
+			if (t.left || t.first) && (t.right || t.last) && len(t.s) > len(longest.s) {
+				longest = t
+			}
+		}
+		return longest
+	default:
+		return term{}
+	}
+}
+
+// isWordRune checks if the rune can be a part of a code search token.
+func isWordRune(r rune) bool {
+	return r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= utf8.RuneSelf
+}
+
+// startsNonWord checks if every match of the expression starts at a non-word boundary.
+func startsNonWord(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText, syntax.OpWordBoundary:
+		return true
+	case syntax.OpLiteral:
+		return !isWordRune(re.Rune[0])
+	case syntax.OpCharClass:
+		return nonWordClass(re.Rune)
+	case syntax.OpCapture, syntax.OpPlus:
+		return startsNonWord(re.Sub[0])
+	case syntax.OpConcat:
+		return len(re.Sub) > 0 && startsNonWord(re.Sub[0])
+	default:
+		return false
+	}
+}
+
+// endsNonWord checks if every match of the expression ends at a non-word boundary.
+func endsNonWord(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText, syntax.OpWordBoundary:
+		return true
+	case syntax.OpLiteral:
+		return !isWordRune(re.Rune[len(re.Rune)-1])
+	case syntax.OpCharClass:
+		return nonWordClass(re.Rune)
+	case syntax.OpCapture, syntax.OpPlus:
+		return endsNonWord(re.Sub[0])
+	case syntax.OpConcat:
+		return len(re.Sub) > 0 && endsNonWord(re.Sub[len(re.Sub)-1])
+	default:
+		return false
+	}
+}
+
+// nonWordClass checks if the character class contains no word characters.
+// Ranges are given as pairs of the lowest and the highest runes.
+func nonWordClass(ranges []rune) bool {
+	if len(ranges) == 0 {
+		return false
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1]; r++ {
+			if isWordRune(r) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// searchPaths uses the code search to find paths of files in the repository
+// that may contain the search term. It returns false if the results can't be
+// relied upon (e.g. the repository is not indexed or the results are incomplete).
+func (f *finder) searchPaths(ctx context.Context, repo *github.Repository, term string) (map[string]bool, bool) {
+	query := fmt.Sprintf("%q repo:%s", term, repo.GetFullName())
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	paths := make(map[string]bool)
+	for {
+		results, resp, err := f.gh.Search.Code(ctx, query, opts)
+		if err != nil {
+			fmt.Fprintf(f.stderr, "%s: code search error, falling back to walking the tree: %s\n", repo.GetFullName(), err)
+			return nil, false
+		}
+		if results.GetIncompleteResults() || results.GetTotal() > maxSearchResults {
+			fmt.Fprintf(f.stderr, "%s: code search results are incomplete, falling back to walking the tree\n", repo.GetFullName())
+			return nil, false
+		}
+
+		for _, result := range results.CodeResults {
+			paths[result.GetPath()] = true
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return paths, true
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestSearchTerm(t *testing.T) {
+	tests := []struct {
+		pattern string
+		term    string
+	}{
+		{"golang.org/x/sync", ""},
+		{`golang\.org/x/sync`, ""},
+		{`\bgolang\.org/x/sync\b`, "golang.org/x/sync"},
+		{"^FROM nginx:1\\.19$", "FROM nginx:1.19"},
+		{"foo|bar", ""},
+		{"(aws-sdk-go) v1", ""},
+		{`\b(aws-sdk-go) v1\b`, "aws-sdk-go"},
+		{`"(aws-sdk-go)" v`, "aws-sdk-go"},
+		{`\bfoo\b.*barbaz`, "foo"},
+		{`[ "]sync[ "]`, "sync"},
+		{"[a-z]+", ""},
+		{"(?i)readme", ""},
+		{`(?i)\breadme\b`, "readme"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.pattern, func(t *testing.T) {
+			t.Parallel()
+
+			if want, got := tt.term, searchTerm(regexp.MustCompile(tt.pattern)); want != got {
+				t.Errorf("Expected %q got %q", want, got)
+			}
+		})
+	}
+}
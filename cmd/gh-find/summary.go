@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"text/tabwriter"
+	"time"
+)
+
+// countingTransport counts API requests and bytes downloaded through it.
+type countingTransport struct {
+	transport http.RoundTripper
+	calls     int
+	bytes     int64
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls++
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &countingReader{ReadCloser: resp.Body, n: &t.bytes}
+
+	return resp, nil
+}
+
+// countingReader counts bytes read from the underlying reader.
+type countingReader struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	*r.n += int64(n)
+
+	return n, err
+}
+
+// repoSummary holds totals for a repository.
+type repoSummary struct {
+	repo    string
+	entries int           // The number of entries scanned.
+	matches int           // The number of matched entries.
+	bytes   int64         // The number of bytes downloaded.
+	calls   int           // The number of API calls.
+	elapsed time.Duration // The time spent on the repository.
+}
+
+// summary collects per repository and overall totals of the run.
+// All methods are no-op on a nil summary so that the walk doesn't
+// have to check whether the summary was requested.
+type summary struct {
+	transport *countingTransport
+	start     time.Time
+	repos     []*repoSummary
+	current   *repoSummary
+	// Counters at the start of the current repository.
+	calls     int
+	bytes     int64
+	repoStart time.Time
+}
+
+func newSummary(transport *countingTransport) *summary {
+	return &summary{
+		transport: transport,
+		start:     time.Now(),
+	}
+}
+
+// startRepo closes the current repository if any and starts a new one.
+func (s *summary) startRepo(name string) {
+	if s == nil {
+		return
+	}
+	s.endRepo()
+
+	s.current = &repoSummary{repo: name}
+	s.repos = append(s.repos, s.current)
+	s.calls = s.transport.calls
+	s.bytes = s.transport.bytes
+	s.repoStart = time.Now()
+}
+
+// endRepo closes the current repository if any.
+func (s *summary) endRepo() {
+	if s == nil || s.current == nil {
+		return
+	}
+
+	s.current.calls = s.transport.calls - s.calls
+	s.current.bytes = s.transport.bytes - s.bytes
+	s.current.elapsed = time.Since(s.repoStart)
+	s.current = nil
+}
+
+func (s *summary) addEntry() {
+	if s == nil || s.current == nil {
+		return
+	}
+	s.current.entries++
+}
+
+func (s *summary) addMatch() {
+	if s == nil || s.current == nil {
+		return
+	}
+	s.current.matches++
+}
+
+// countBytes counts bytes downloaded outside of the API (e.g. tarballs).
+func (s *summary) countBytes(r io.ReadCloser) io.ReadCloser {
+	if s == nil {
+		return r
+	}
+
+	return &countingReader{ReadCloser: r, n: &s.transport.bytes}
+}
+
+// write writes the summary table.
+func (s *summary) write(w io.Writer) error {
+	if s == nil {
+		return nil
+	}
+	s.endRepo()
+
+	total := repoSummary{repo: "Total", calls: s.transport.calls, bytes: s.transport.bytes, elapsed: time.Since(s.start)}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Repository\tEntries\tMatches\tBytes\tAPI calls\tElapsed\t")
+	for _, r := range append(s.repos, &total) {
+		if r != &total {
+			total.entries += r.entries
+			total.matches += r.matches
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\t\n", r.repo, r.entries, r.matches, r.bytes, r.calls, r.elapsed.Round(time.Millisecond))
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestSummary(t *testing.T) {
+	transport := &countingTransport{transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("hello"))}, nil
+	})}
+	client := &http.Client{Transport: transport}
+	get := func() {
+		resp, err := client.Get("https://api.github.com/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+
+	s := newSummary(transport)
+	s.startRepo("foo/bar")
+	get()
+	s.addEntry()
+	s.addEntry()
+	s.addMatch()
+	s.startRepo("foo/baz")
+	get()
+	get()
+	s.addEntry()
+	s.endRepo()
+
+	if want, got := 2, len(s.repos); want != got {
+		t.Fatalf("Expected %d repos got %d", want, got)
+	}
+	tests := []struct {
+		repo    string
+		entries int
+		matches int
+		calls   int
+		bytes   int64
+	}{
+		{"foo/bar", 2, 1, 1, 5},
+		{"foo/baz", 1, 0, 2, 10},
+	}
+	for i, tt := range tests {
+		r := s.repos[i]
+		if r.repo != tt.repo || r.entries != tt.entries || r.matches != tt.matches || r.calls != tt.calls || r.bytes != tt.bytes {
+			t.Errorf("Expected %+v got %+v", tt, *r)
+		}
+	}
+
+	var sb strings.Builder
+	if err := s.write(&sb); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	if want, got := 4, len(lines); want != got {
+		t.Fatalf("Expected %d lines got %d", want, got)
+	}
+	if want, got := []string{"Total", "3", "1", "15", "3"}, strings.Fields(lines[3])[:5]; strings.Join(want, " ") != strings.Join(got, " ") {
+		t.Errorf("Expected %v got %v", want, got)
+	}
+}
+
+func TestNilSummary(t *testing.T) {
+	var s *summary
+	s.startRepo("foo/bar")
+	s.addEntry()
+	s.addMatch()
+	s.endRepo()
+	if err := s.write(ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+}
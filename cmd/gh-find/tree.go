@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// fetchTree fetches entries of a tree which recursive listing was truncated
+// directory by directory. Subtrees are fetched recursively and fetched
+// directory by directory in turn only if they're truncated as well.
+// Entry paths are made relative to the repository root.
+func (f *finder) fetchTree(ctx context.Context, repo *github.Repository, sha, prefix string) ([]*github.TreeEntry, error) {
+	tree, _, err := f.gh.Git.GetTree(ctx, repo.GetOwner().GetLogin(), repo.GetName(), sha, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*github.TreeEntry
+	for _, entry := range tree.Entries {
+		prefixPath(entry, prefix)
+		entries = append(entries, entry)
+
+		if entry.GetType() != "tree" {
+			continue
+		}
+
+		subtree, _, err := f.gh.Git.GetTree(ctx, repo.GetOwner().GetLogin(), repo.GetName(), entry.GetSHA(), true)
+		if err != nil {
+			return nil, err
+		}
+		if subtree.GetTruncated() {
+			subentries, err := f.fetchTree(ctx, repo, entry.GetSHA(), entry.GetPath())
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, subentries...)
+			continue
+		}
+		for _, subentry := range subtree.Entries {
+			prefixPath(subentry, entry.GetPath())
+			entries = append(entries, subentry)
+		}
+	}
+
+	return entries, nil
+}
+
+// prefixPath prepends the entry path with the prefix.
+func prefixPath(entry *github.TreeEntry, prefix string) {
+	if prefix == "" {
+		return
+	}
+	entry.Path = github.String(path.Join(prefix, entry.GetPath()))
+}
+
+// getEntries returns all entries of the repository tree at the ref.
+// No entries are returned if the ref doesn't exist or the repository is empty.
+func (f *finder) getEntries(ctx context.Context, repo *github.Repository, ref string) ([]*github.TreeEntry, error) {
+	sha := ref
+	if f.cache != nil {
+		// Resolve the ref to the commit SHA to look up the cache.
+		var (
+			resp *github.Response
+			err  error
+		)
+		sha, resp, err = f.gh.Repositories.GetCommitSHA1(ctx, repo.GetOwner().GetLogin(), repo.GetName(), ref, "")
+		if err != nil {
+			if isNotFound(resp) {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		if entries, ok := f.cache.get(repo.GetFullName(), sha); ok {
+			return entries, nil
+		}
+	}
+
+	tree, resp, err := f.gh.Git.GetTree(ctx, repo.GetOwner().GetLogin(), repo.GetName(), sha, true)
+	if err != nil {
+		if isNotFound(resp) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	entries := tree.Entries
+	if tree.GetTruncated() {
+		// Fetch the tree directory by directory to get complete results.
+		entries, err = f.fetchTree(ctx, repo, tree.GetSHA(), "")
+		if err != nil {
+			return nil, fmt.Errorf("%s: error fetching truncated tree: %w", repo.GetFullName(), err)
+		}
+	}
+
+	if f.cache != nil {
+		if err = f.cache.put(repo.GetFullName(), sha, entries); err != nil {
+			fmt.Fprintf(f.stderr, "%s: error caching tree: %s\n", repo.GetFullName(), err)
+		}
+	}
+
+	return entries, nil
+}
+
+// isNotFound checks if the ref or the repository doesn't exist or the repository is empty.
+func isNotFound(resp *github.Response) bool {
+	// http.StatusConflict - Git Repository is empty.
+	return resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusConflict)
+}
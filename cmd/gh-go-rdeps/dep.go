@@ -21,6 +21,20 @@ type Gopkg struct {
 	Required    []string       `toml:"required,omitempty"`
 }
 
+// GopkgLock represents a Gopkg.lock file.
+type GopkgLock struct {
+	Projects []GopkgLockProject `toml:"projects"`
+}
+
+type GopkgLockProject struct {
+	Name     string   `toml:"name"`
+	Branch   string   `toml:"branch,omitempty"`
+	Revision string   `toml:"revision"`
+	Version  string   `toml:"version,omitempty"`
+	Source   string   `toml:"source,omitempty"`
+	Packages []string `toml:"packages"`
+}
+
 func parseGopkg(r io.Reader) (*Gopkg, error) {
 	gopkg := &Gopkg{}
 	err := toml.NewDecoder(r).Decode(gopkg)
@@ -30,3 +44,66 @@ func parseGopkg(r io.Reader) (*Gopkg, error) {
 
 	return gopkg, nil
 }
+
+func parseGopkgLock(r io.Reader) (*GopkgLock, error) {
+	lock := &GopkgLock{}
+	err := toml.NewDecoder(r).Decode(lock)
+	if err != nil {
+		return nil, err
+	}
+
+	return lock, nil
+}
+
+// gopkgDependency looks up the path in constraints and overrides
+// of the Gopkg.toml file. It returns nil if there is no dependency on the path.
+func gopkgDependency(gopkg *Gopkg, target target) *dependency {
+	for _, projects := range [][]GopkgProject{gopkg.Constraints, gopkg.Overrides} {
+		for _, project := range projects {
+			if target.match(project.Name) || target.match(project.Source) {
+				return &dependency{
+					Path:    project.Name,
+					Version: project.constraint(),
+					Via:     viaDep,
+					Direct:  true,
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// constraint returns the version, the branch or the revision the project is constrained to.
+func (p GopkgProject) constraint() string {
+	switch {
+	case p.Version != "":
+		return p.Version
+	case p.Branch != "":
+		return "branch " + p.Branch
+	case p.Revision != "":
+		return "revision " + p.Revision
+	default:
+		return ""
+	}
+}
+
+// gopkgLockDependency looks up the path in projects of the Gopkg.lock file.
+// It returns nil if there is no dependency on the path.
+func gopkgLockDependency(lock *GopkgLock, target target) *dependency {
+	for _, project := range lock.Projects {
+		if target.match(project.Name) || target.match(project.Source) {
+			return &dependency{
+				Path: project.Name,
+				Version: GopkgProject{
+					Branch:   project.Branch,
+					Revision: project.Revision,
+					Version:  project.Version,
+				}.constraint(),
+				Via: viaDep,
+			}
+		}
+	}
+
+	return nil
+}
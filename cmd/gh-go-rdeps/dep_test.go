@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGopkgDependency(t *testing.T) {
+	tests := []struct {
+		desc  string
+		gopkg string
+		want  *dependency
+	}{
+		{
+			desc:  "no dependency",
+			gopkg: "[[constraint]]\n  name = \"github.com/foo/baz\"\n  version = \"1.0.0\"\n",
+		},
+		{
+			desc:  "version",
+			gopkg: "[[constraint]]\n  name = \"github.com/foo/bar\"\n  version = \"^1.2.0\"\n",
+			want:  &dependency{Path: "github.com/foo/bar", Version: "^1.2.0", Via: viaDep, Direct: true},
+		},
+		{
+			desc:  "branch override",
+			gopkg: "[[override]]\n  name = \"github.com/foo/bar\"\n  branch = \"main\"\n",
+			want:  &dependency{Path: "github.com/foo/bar", Version: "branch main", Via: viaDep, Direct: true},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			gopkg, err := parseGopkg(strings.NewReader(tt.gopkg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tt.want, gopkgDependency(gopkg, target{path: "github.com/foo/bar"}); !reflect.DeepEqual(want, got) {
+				t.Errorf("Expected %+v got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestGopkgLockDependency(t *testing.T) {
+	lock, err := parseGopkgLock(strings.NewReader(`[[projects]]
+  digest = "1:abc"
+  name = "github.com/foo/bar"
+  packages = ["."]
+  revision = "0123456789abcdef"
+  version = "v1.2.3"
+
+[[projects]]
+  branch = "master"
+  name = "github.com/foo/baz"
+  packages = ["."]
+  revision = "fedcba9876543210"
+
+[solve-meta]
+  analyzer-name = "dep"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &dependency{Path: "github.com/foo/bar", Version: "v1.2.3", Via: viaDep}
+	if got := gopkgLockDependency(lock, target{path: "github.com/foo/bar"}); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected %+v got %+v", want, got)
+	}
+	want = &dependency{Path: "github.com/foo/baz", Version: "branch master", Via: viaDep}
+	if got := gopkgLockDependency(lock, target{path: "github.com/foo/baz"}); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected %+v got %+v", want, got)
+	}
+	if got := gopkgLockDependency(lock, target{path: "github.com/foo/qux"}); got != nil {
+		t.Errorf("Expected nil got %+v", got)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// importDependency looks up the path in import declarations of .go files
+// of the repository. It's used for legacy GOPATH style repositories
+// without dependency manager files. It returns nil if no file imports the path.
+func (f *finder) importDependency(ctx context.Context, repo *github.Repository, tree *repoTree) (*dependency, error) {
+	for _, entry := range tree.goFiles {
+		if vendored(entry.GetPath()) {
+			continue
+		}
+
+		src, _, err := f.gh.Git.GetBlobRaw(ctx, repo.GetOwner().GetLogin(), repo.GetName(), entry.GetSHA())
+		if err != nil {
+			return nil, err
+		}
+
+		if path := importPath(src, f.target()); path != "" {
+			return &dependency{
+				Path:   path,
+				Via:    viaImports,
+				Direct: true,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// importPath returns the first import path of the Go source file matching the target.
+// It returns an empty string if there is no such import or the file can't be parsed.
+func importPath(src []byte, target target) string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ImportsOnly)
+	if err != nil {
+		return ""
+	}
+
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if target.match(path) {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// vendored reports whether the path is within a vendor or testdata directory.
+func vendored(path string) bool {
+	for _, dir := range strings.Split(path, "/") {
+		if dir == "vendor" || dir == "testdata" {
+			return true
+		}
+	}
+
+	return false
+}
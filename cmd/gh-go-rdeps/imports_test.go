@@ -0,0 +1,53 @@
+package main
+
+import "testing"
+
+func TestImportPath(t *testing.T) {
+	src := `package main
+
+import (
+	"fmt"
+
+	bar "github.com/foo/bar/client"
+)
+
+import "github.com/foo/baz"
+
+func main() {
+	fmt.Println(bar.New(), baz.New())
+}
+`
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"github.com/foo/bar", "github.com/foo/bar/client"},
+		{"github.com/foo/baz", "github.com/foo/baz"},
+		{"github.com/foo/qux", ""},
+		{"github.com/foo/ba", ""},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.want, importPath([]byte(src), target{path: tt.path}); want != got {
+			t.Errorf("%s: expected %q got %q", tt.path, want, got)
+		}
+	}
+
+	if got := importPath([]byte("not go"), target{path: "github.com/foo/bar"}); got != "" {
+		t.Errorf("Expected an empty path got %q", got)
+	}
+}
+
+func TestVendored(t *testing.T) {
+	for path, want := range map[string]bool{
+		"main.go":                    false,
+		"vendor/github.com/foo/a.go": true,
+		"cmd/app/vendor/x/a.go":      true,
+		"pkg/testdata/a.go":          true,
+		"pkg/vendored/a.go":          false,
+	} {
+		if got := vendored(path); want != got {
+			t.Errorf("%s: expected %t got %t", path, want, got)
+		}
+	}
+}
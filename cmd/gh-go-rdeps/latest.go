@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const defaultProxy = "https://proxy.golang.org"
+
+// moduleProxy returns the first module proxy URL from GOPROXY.
+func moduleProxy() string {
+	for _, proxy := range strings.FieldsFunc(os.Getenv("GOPROXY"), func(r rune) bool { return r == ',' || r == '|' }) {
+		if strings.HasPrefix(proxy, "https://") || strings.HasPrefix(proxy, "http://") {
+			return strings.TrimSuffix(proxy, "/")
+		}
+	}
+
+	return defaultProxy
+}
+
+// latestVersion resolves the latest version of the module across major versions
+// using the module proxy. Higher major versions are probed at /vN paths until the
+// proxy doesn't know the module. Modules unknown to the proxy (e.g. private ones)
+// hosted on GitHub are resolved using repository tags. It returns an empty string
+// if the version can't be resolved.
+func (f *finder) latestVersion(ctx context.Context, modpath string) (string, error) {
+	if version, ok := f.latest[modpath]; ok {
+		return version, nil
+	}
+
+	version, err := f.proxyLatestVersion(ctx, modpath)
+	if err != nil {
+		return "", err
+	}
+	if version == "" && strings.HasPrefix(modpath, "github.com/") {
+		version, err = f.tagLatestVersion(ctx, modpath)
+		if err != nil {
+			return "", err
+		}
+	}
+	if version != "" {
+		for major := pathMajorVersion(modpath) + 1; ; major++ {
+			next, err := f.proxyLatestVersion(ctx, majorPath(modpath, major))
+			if err != nil {
+				return "", err
+			}
+			if next == "" {
+				break
+			}
+			version = next
+		}
+	}
+	f.latest[modpath] = version
+
+	return version, nil
+}
+
+// pathMajorVersion returns the major version of the module path
+// e.g. 1 for github.com/foo/bar and 2 for github.com/foo/bar/v2.
+func pathMajorVersion(modpath string) int {
+	_, pathMajor, _ := module.SplitPathVersion(modpath)
+	major, err := strconv.Atoi(strings.TrimLeft(pathMajor, "/.v"))
+	if err != nil || major < 1 && !strings.HasPrefix(modpath, "gopkg.in/") {
+		return 1
+	}
+
+	return major
+}
+
+// majorPath returns the path of the major version of the module.
+func majorPath(modpath string, major int) string {
+	prefix, _, _ := module.SplitPathVersion(modpath)
+	if strings.HasPrefix(modpath, "gopkg.in/") {
+		return fmt.Sprintf("%s.v%d", prefix, major)
+	}
+	if major < 2 {
+		return prefix
+	}
+
+	return fmt.Sprintf("%s/v%d", prefix, major)
+}
+
+// proxyLatestVersion queries the module proxy $GOPROXY/<module>/@latest endpoint.
+func (f *finder) proxyLatestVersion(ctx context.Context, modpath string) (string, error) {
+	escaped, err := module.EscapePath(modpath)
+	if err != nil {
+		return "", nil // Not a module path.
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.proxy+"/"+escaped+"/@latest", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusGone:
+		return "", nil
+	default:
+		return "", fmt.Errorf("%s: module proxy error: %s", modpath, resp.Status)
+	}
+
+	info := struct{ Version string }{}
+	if err = json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", fmt.Errorf("%s: module proxy error: %w", modpath, err)
+	}
+
+	return info.Version, nil
+}
+
+// tagLatestVersion looks up the highest semver tag of the GitHub repository
+// regardless of the major version of the module path.
+func (f *finder) tagLatestVersion(ctx context.Context, modpath string) (string, error) {
+	parts := strings.Split(modpath, "/")
+	if len(parts) < 3 {
+		return "", nil
+	}
+
+	var (
+		latest string
+		opts   = &github.ListOptions{PerPage: 100}
+	)
+	for {
+		tags, resp, err := f.gh.Repositories.ListTags(ctx, parts[1], parts[2], opts)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return "", nil
+			}
+			return "", err
+		}
+
+		for _, tag := range tags {
+			version := tag.GetName()
+			if !semver.IsValid(version) || semver.Prerelease(version) != "" {
+				continue
+			}
+			if latest == "" || semver.Compare(version, latest) > 0 {
+				latest = version
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return latest, nil
+}
+
+// staleVersion reports whether the required version is behind
+// the major or minor version of the latest one.
+// Versions that can't be compared e.g. branches are not considered stale.
+func staleVersion(version, latest string) bool {
+	fields := strings.Fields(version)
+	if len(fields) == 0 || !semver.IsValid(latest) {
+		return false
+	}
+
+	// Gopkg.toml constraints e.g. ^1.2.0.
+	version = strings.TrimLeft(fields[0], "^~=<>")
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	if !semver.IsValid(version) {
+		return false
+	}
+
+	return semver.Compare(semver.MajorMinor(version), semver.MajorMinor(latest)) < 0
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStaleVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		latest  string
+		want    bool
+	}{
+		{"v1.2.3", "v1.4.0", true},
+		{"v1.4.1", "v1.4.0", false},
+		{"v1.4.0 => github.com/fork/bar v1.4.1", "v1.5.0", true},
+		{"v0.0.0-20201020160332-67f06af15bc9", "v0.1.0", true},
+		{"=> ../bar", "v1.4.0", false},
+		{"^1.2.0", "v1.4.0", true},
+		{"1.4.0", "v1.4.0", false},
+		{"branch master", "v1.4.0", false},
+		{"v1.2.3", "", false},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.want, staleVersion(tt.version, tt.latest); want != got {
+			t.Errorf("%s %s: expected %t got %t", tt.version, tt.latest, want, got)
+		}
+	}
+}
+
+func TestMajorPath(t *testing.T) {
+	tests := []struct {
+		modpath string
+		major   int
+		current int
+		want    string
+	}{
+		{"github.com/foo/bar", 2, 1, "github.com/foo/bar/v2"},
+		{"github.com/foo/bar/v2", 3, 2, "github.com/foo/bar/v3"},
+		{"github.com/foo/bar/v2", 1, 2, "github.com/foo/bar"},
+		{"gopkg.in/yaml.v2", 3, 2, "gopkg.in/yaml.v3"},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.current, pathMajorVersion(tt.modpath); want != got {
+			t.Errorf("%s: expected major %d got %d", tt.modpath, want, got)
+		}
+		if want, got := tt.want, majorPath(tt.modpath, tt.major); want != got {
+			t.Errorf("%s %d: expected %s got %s", tt.modpath, tt.major, want, got)
+		}
+	}
+}
+
+func TestLatestVersion(t *testing.T) {
+	versions := map[string]string{
+		"/github.com/foo/bar/@latest":    "v1.9.0",
+		"/github.com/foo/bar/v2/@latest": "v2.3.0",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, ok := versions[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"Version":%q}`, version)
+	}))
+	defer server.Close()
+
+	f := &finder{proxy: server.URL, latest: map[string]string{}}
+	for _, modpath := range []string{"github.com/foo/bar", "github.com/foo/bar/v2"} {
+		latest, err := f.latestVersion(context.Background(), modpath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := "v2.3.0", latest; want != got {
+			t.Errorf("%s: expected %s got %s", modpath, want, got)
+		}
+	}
+
+	if !staleVersion("v1.9.0", "v2.3.0") {
+		t.Errorf("Expected v1.9.0 to be stale")
+	}
+}
@@ -3,11 +3,15 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"path"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
@@ -15,6 +19,7 @@ import (
 	"github.com/google/go-github/v32/github"
 	"github.com/pmatseykanets/gh-tools/auth"
 	gh "github.com/pmatseykanets/gh-tools/github"
+	"github.com/pmatseykanets/gh-tools/pool"
 	"github.com/pmatseykanets/gh-tools/terminal"
 	"github.com/pmatseykanets/gh-tools/version"
 	"golang.org/x/mod/modfile"
@@ -26,14 +31,30 @@ func usage() {
 
 Usage: gh-go-rdeps [flags] <owner> <path>
   owner         Repository owner (user or organization)
-  path          Module/package path
+  path          Module/package path (package name for js and python)
 
 Flags:
-  -help         Print this information and exit
-  -no-repo=     The pattern to reject repository names
-  -repo=        The pattern to match repository names
-  -token        Prompt for an Access Token
-  -version      Print the version and exit
+  -archived         Include archived repositories
+  -branch=          The branch name if different from the default
+  -check-latest     Report only dependents pinned to older major/minor versions
+  -concurrency=     The number of repositories to scan in parallel (default 4)
+  -direct-only      Report only direct dependencies
+  -emit-repo-list=  Write dependent repository names (owner/repo) one per line to the file
+  -exact            Match the module path exactly
+  -format=          The output format text (default), json (one object per line) or repos
+  -help             Print this information and exit
+  -lang=            The language ecosystem go (default), js or python
+  -no-cache         Don't cache API responses in the user cache directory
+                       (e.g. ~/.cache/gh-tools) between runs
+  -no-fork          Don't include fork repositories
+  -no-private       Don't include private repositories
+  -no-public        Don't include public repositories
+  -no-repo=         The pattern to reject repository names
+  -replaces-only    Report only dependencies with a replace directive
+  -repo=            The pattern to match repository names
+  -scan-imports     Scan imports of .go files in repositories without go.mod or Gopkg.toml
+  -token            Prompt for an Access Token
+  -version          Print the version and exit
 `
 	fmt.Println(usage)
 }
@@ -51,13 +72,42 @@ type config struct {
 	repoRegexp   *regexp.Regexp
 	token        bool           // Propmt for an access token.
 	noRepoRegexp *regexp.Regexp // The pattern to reject repository names.
+	branch       string         // The branch name if different from the default.
+	format       string         // The output format.
+	archived     bool           // Include archived repositories.
+	noPrivate    bool           // Don't include private repositories.
+	noPublic     bool           // Don't include public repositories.
+	noFork       bool           // Don't include fork repositories.
+	concurrency  int            // The number of repositories to scan in parallel.
+	directOnly   bool           // Report only direct dependencies.
+	checkLatest  bool           // Report only dependencies behind the latest version.
+	exact        bool           // Match the module path exactly.
+	scanImports  bool           // Scan imports of .go files as a fallback.
+	replacesOnly bool           // Report only replaced dependencies.
+	lang         string         // The language ecosystem.
+	noCache      bool           // Don't cache API responses.
+	emitRepoList string         // The file to write dependent repository names to.
 }
 
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatRepos = "repos"
+)
+
+const (
+	langGo     = "go"
+	langJS     = "js"
+	langPython = "python"
+)
+
 type finder struct {
 	gh     *github.Client
 	config config
 	stdout io.WriteCloser
 	stderr io.WriteCloser
+	proxy  string            // The module proxy URL.
+	latest map[string]string // Latest versions keyed by the module path.
 }
 
 func readConfig() (config, error) {
@@ -66,7 +116,11 @@ func readConfig() (config, error) {
 		os.Exit(1)
 	}
 
-	config := config{}
+	config := config{
+		format:      formatText,
+		concurrency: 4,
+		lang:        langGo,
+	}
 
 	var (
 		showVersion, showHelp bool
@@ -74,9 +128,24 @@ func readConfig() (config, error) {
 		err                   error
 	)
 
+	flag.BoolVar(&config.archived, "archived", config.archived, "Include archived repositories")
+	flag.StringVar(&config.branch, "branch", "", "The branch name if different from the default")
+	flag.BoolVar(&config.checkLatest, "check-latest", config.checkLatest, "Report only dependents pinned to older major/minor versions")
+	flag.IntVar(&config.concurrency, "concurrency", config.concurrency, "The number of repositories to scan in parallel")
+	flag.BoolVar(&config.directOnly, "direct-only", config.directOnly, "Report only direct dependencies")
+	flag.StringVar(&config.emitRepoList, "emit-repo-list", "", "Write dependent repository names to the file")
+	flag.BoolVar(&config.exact, "exact", config.exact, "Match the module path exactly")
+	flag.StringVar(&config.format, "format", config.format, "The output format text, json or repos")
 	flag.BoolVar(&showHelp, "help", showHelp, "Print this information and exit")
+	flag.StringVar(&config.lang, "lang", config.lang, "The language ecosystem go, js or python")
+	flag.BoolVar(&config.noCache, "no-cache", config.noCache, "Don't cache API responses")
+	flag.BoolVar(&config.noFork, "no-fork", config.noFork, "Don't include fork repositories")
+	flag.BoolVar(&config.noPrivate, "no-private", config.noPrivate, "Don't include private repositories")
+	flag.BoolVar(&config.noPublic, "no-public", config.noPublic, "Don't include public repositories")
 	flag.StringVar(&noRepo, "no-repo", "", "The pattern to reject repository names")
 	flag.StringVar(&repo, "repo", "", "The pattern to match repository names")
+	flag.BoolVar(&config.replacesOnly, "replaces-only", config.replacesOnly, "Report only dependencies with a replace directive")
+	flag.BoolVar(&config.scanImports, "scan-imports", config.scanImports, "Scan imports of .go files in repositories without go.mod or Gopkg.toml")
 	flag.BoolVar(&config.token, "token", config.token, "Prompt for Access Token")
 	flag.BoolVar(&showVersion, "version", showVersion, "Print version and exit")
 	flag.Usage = usage
@@ -108,6 +177,33 @@ func readConfig() (config, error) {
 		return config, fmt.Errorf("mod path can't be empty")
 	}
 
+	if config.concurrency < 1 {
+		return config, fmt.Errorf("concurrency should be positive")
+	}
+
+	if config.noPrivate && config.noPublic {
+		return config, fmt.Errorf("no-private and no-public are mutually exclusive")
+	}
+
+	switch config.lang {
+	case langGo:
+	case langJS, langPython:
+		if config.checkLatest {
+			return config, fmt.Errorf("check-latest is only supported for go")
+		}
+		if config.scanImports {
+			return config, fmt.Errorf("scan-imports is only supported for go")
+		}
+	default:
+		return config, fmt.Errorf("invalid lang: %s", config.lang)
+	}
+
+	switch config.format {
+	case formatText, formatJSON, formatRepos:
+	default:
+		return config, fmt.Errorf("invalid format: %s", config.format)
+	}
+
 	if repo != "" {
 		config.repoRegexp, err = regexp.Compile(repo)
 		if err != nil {
@@ -130,6 +226,8 @@ func run(ctx context.Context) error {
 	finder := &finder{
 		stdout: os.Stdout,
 		stderr: os.Stderr,
+		proxy:  moduleProxy(),
+		latest: map[string]string{},
 	}
 	finder.config, err = readConfig()
 	if err != nil {
@@ -146,9 +244,18 @@ func run(ctx context.Context) error {
 		return fmt.Errorf("access token is required")
 	}
 
-	finder.gh = github.NewClient(oauth2.NewClient(ctx, oauth2.StaticTokenSource(
+	httpClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
-	)))
+	))
+	if !finder.config.noCache {
+		// The cache sits below the oauth2 transport so that responses are keyed by the access token.
+		if dir, err := os.UserCacheDir(); err != nil {
+			fmt.Fprintf(finder.stderr, "Response cache is disabled: %s\n", err)
+		} else if transport, ok := httpClient.Transport.(*oauth2.Transport); ok {
+			transport.Base = gh.NewCacheTransport(transport.Base, filepath.Join(dir, "gh-tools", "responses"))
+		}
+	}
+	finder.gh = github.NewClient(httpClient)
 
 	return finder.find(ctx)
 }
@@ -158,105 +265,346 @@ func (f *finder) find(ctx context.Context) error {
 		Owner:        f.config.owner,
 		RepoRegexp:   f.config.repoRegexp,
 		NoRepoRegexp: f.config.noRepoRegexp,
+		Archived:     f.config.archived,
+		NoPrivate:    f.config.noPrivate,
+		NoPublic:     f.config.noPublic,
+		NoFork:       f.config.noFork,
 	})
 	if err != nil {
 		return err
 	}
 
+	results, err := f.scan(ctx, repos)
+	if err != nil {
+		return err
+	}
+
+	var dependencies []*dependency
+	for _, deps := range results {
+		for _, dep := range deps {
+			if f.config.directOnly && !dep.Direct {
+				continue
+			}
+			if f.config.replacesOnly && !dep.Replaced {
+				continue
+			}
+			if f.config.checkLatest {
+				latest, err := f.latestVersion(ctx, dep.Path)
+				if err != nil {
+					return err
+				}
+				if !staleVersion(dep.Version, latest) {
+					continue
+				}
+				dep.Latest = latest
+			}
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	sort.Slice(dependencies, func(i, j int) bool {
+		return dependencies[i].Module < dependencies[j].Module
+	})
+
+	printed := map[string]bool{} // A repository can have several dependent modules.
+	for _, dependency := range dependencies {
+		if f.config.format == formatRepos {
+			if printed[dependency.Repo] {
+				continue
+			}
+			printed[dependency.Repo] = true
+		}
+		if err = f.print(dependency); err != nil {
+			return err
+		}
+	}
+
+	if f.config.emitRepoList != "" {
+		return f.emitRepoList(dependencies)
+	}
+
+	return nil
+}
+
+// emitRepoList writes full names of dependent repositories one per line
+// to the file in the format accepted by -repo-file of other tools.
+func (f *finder) emitRepoList(dependencies []*dependency) error {
 	var (
-		repo         *github.Repository
-		goRepo       bool
-		contents     []byte
-		mod          *modfile.File
-		require      *modfile.Require
-		replace      *modfile.Replace
-		gopkg        *Gopkg
-		gopkgProject GopkgProject
-		dependencies []string
+		names []string
+		seen  = map[string]bool{}
 	)
-nextRepo:
-	for _, repo = range repos {
-		goRepo, err = f.goRepo(ctx, repo)
+	for _, dep := range dependencies {
+		if !seen[dep.Repo] {
+			seen[dep.Repo] = true
+			names = append(names, dep.Repo)
+		}
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Repositories that depend on %s\n", f.config.modpath)
+	for _, name := range names {
+		fmt.Fprintln(&buf, name)
+	}
+
+	if err := ioutil.WriteFile(f.config.emitRepoList, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error writing repo list: %w", err)
+	}
+
+	return nil
+}
+
+// scan looks up dependencies of repositories in parallel using a bounded pool of workers.
+// Results are returned in the order of repositories. The first error stops the scan.
+func (f *finder) scan(ctx context.Context, repos []*github.Repository) ([][]*dependency, error) {
+	results := make([][]*dependency, len(repos))
+	err := pool.Run(ctx, f.config.concurrency, len(repos), func(ctx context.Context, i int) error {
+		deps, err := f.repoDependencies(ctx, repos[i], f.repoBranch(repos[i]))
 		if err != nil {
 			return err
 		}
+		for _, dep := range deps {
+			dep.URL = repos[i].GetHTMLURL()
+		}
+		results[i] = deps
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		if !goRepo {
-			continue
+	return results, nil
+}
+
+// repoBranch returns the branch to look up dependencies in.
+func (f *finder) repoBranch(repo *github.Repository) string {
+	if f.config.branch != "" {
+		return f.config.branch
+	}
+
+	return repo.GetDefaultBranch()
+}
+
+// repoDependencies looks up the module path in the dependency manager files of the repository.
+func (f *finder) repoDependencies(ctx context.Context, repo *github.Repository, branch string) ([]*dependency, error) {
+	tree, err := f.getTree(ctx, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	if tree == nil {
+		return nil, nil
+	}
+
+	switch f.config.lang {
+	case langJS:
+		return f.jsDependencies(ctx, repo, branch, tree)
+	case langPython:
+		return f.pythonDependencies(ctx, repo, branch, tree)
+	}
+
+	if !tree.goRepo {
+		return nil, nil
+	}
+
+	var (
+		contents []byte
+		dep      *dependency
+	)
+
+	// go workspaces take precedence.
+	if tree.has("go.work") {
+		contents, err = f.getFileContents(ctx, repo, branch, "go.work")
+		if err != nil {
+			return nil, err
+		}
+
+		if len(contents) > 0 {
+			return f.workDependencies(ctx, repo, branch, contents)
 		}
+	}
 
-		// go modules take precedence.
-		contents, err = f.getFileContents(ctx, repo, "go.mod")
+	// go modules.
+	if tree.has("go.mod") {
+		contents, err = f.getFileContents(ctx, repo, branch, "go.mod")
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		if len(contents) > 0 {
-			mod, err = modfile.Parse("go.mod", contents, nil)
+			mod, err := modfile.Parse("go.mod", contents, nil)
 			if err != nil {
-				return err
+				return nil, err
 			}
 
-			for _, require = range mod.Require {
-				if strings.HasPrefix(require.Mod.Path, f.config.modpath) {
-					dependencies = append(dependencies, mod.Module.Mod.Path)
-					continue nextRepo
+			dep = modDependency(mod, f.target())
+			// The dependency can be vendored without being listed in go.mod.
+			if dep == nil && tree.has("vendor/modules.txt") {
+				contents, err = f.getFileContents(ctx, repo, branch, "vendor/modules.txt")
+				if err != nil {
+					return nil, err
 				}
-			}
-			for _, replace = range mod.Replace {
-				if strings.HasPrefix(replace.Old.Path, f.config.modpath) ||
-					strings.HasPrefix(replace.New.Path, f.config.modpath) {
-					dependencies = append(dependencies, mod.Module.Mod.Path)
-					continue nextRepo
+
+				if dep = vendorDependency(contents, f.target()); dep != nil && mod.Module != nil {
+					dep.Module = mod.Module.Mod.Path
 				}
 			}
-			continue nextRepo
+
+			if dep == nil {
+				return nil, nil
+			}
+			dep.Repo = repo.GetFullName()
+
+			return []*dependency{dep}, nil
 		}
+	}
 
-		// Gopkg.toml.
-		contents, err = f.getFileContents(ctx, repo, "Gopkg.toml")
+	// Gopkg.toml.
+	if tree.has("Gopkg.toml") {
+		contents, err = f.getFileContents(ctx, repo, branch, "Gopkg.toml")
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		if len(contents) == 0 {
-			continue nextRepo
+		if len(contents) > 0 {
+			gopkg, err := parseGopkg(bytes.NewReader(contents))
+			if err != nil {
+				return nil, err
+			}
+
+			dep = gopkgDependency(gopkg, f.target())
 		}
+	}
 
-		gopkg, err = parseGopkg(bytes.NewReader(contents))
+	// Gopkg.lock lists transitive dependencies that are not constrained in Gopkg.toml.
+	if dep == nil && tree.has("Gopkg.lock") {
+		contents, err = f.getFileContents(ctx, repo, branch, "Gopkg.lock")
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		for _, gopkgProject = range gopkg.Constraints {
-			if strings.HasPrefix(gopkgProject.Name, f.config.modpath) ||
-				strings.HasPrefix(gopkgProject.Source, f.config.modpath) {
-				dependencies = append(dependencies, fmt.Sprintf("github.com/%s/%s", f.config.owner, repo.GetName()))
-				continue nextRepo
+		if len(contents) > 0 {
+			lock, err := parseGopkgLock(bytes.NewReader(contents))
+			if err != nil {
+				return nil, err
 			}
+
+			dep = gopkgLockDependency(lock, f.target())
 		}
-		for _, gopkgProject = range gopkg.Overrides {
-			if strings.HasPrefix(gopkgProject.Name, f.config.modpath) ||
-				strings.HasPrefix(gopkgProject.Source, f.config.modpath) {
-				dependencies = append(dependencies, fmt.Sprintf("github.com/%s/%s", f.config.owner, repo.GetName()))
-				continue nextRepo
-			}
+	}
+
+	// Legacy GOPATH style repositories.
+	if dep == nil && f.config.scanImports && !tree.paths["Gopkg.toml"] {
+		dep, err = f.importDependency(ctx, repo, tree)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	sort.Strings(dependencies)
+	if dep == nil {
+		return nil, nil
+	}
+	dep.Repo = repo.GetFullName()
+	dep.Module = fmt.Sprintf("github.com/%s", repo.GetFullName())
 
-	for _, dependency := range dependencies {
-		fmt.Fprintln(f.stdout, dependency)
+	return []*dependency{dep}, nil
+}
+
+// workDependencies looks up the module path in the go.work file
+// and go.mod files of the workspace modules.
+func (f *finder) workDependencies(ctx context.Context, repo *github.Repository, branch string, contents []byte) ([]*dependency, error) {
+	work, err := parseWork(contents)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	var (
+		mods    []*modfile.File
+		modDirs = map[string]string{} // Workspace module directories keyed by the module path.
+	)
+	for _, dir := range work.localUse() {
+		contents, err = f.getFileContents(ctx, repo, branch, path.Join(dir, "go.mod"))
+		if err != nil {
+			return nil, err
+		}
+		if len(contents) == 0 {
+			continue
+		}
+
+		mod, err := modfile.Parse(path.Join(dir, "go.mod"), contents, nil)
+		if err != nil {
+			return nil, err
+		}
+		if mod.Module != nil {
+			modDirs[mod.Module.Mod.Path] = dir
+		}
+		mods = append(mods, mod)
+	}
+
+	var deps []*dependency
+	for _, mod := range mods {
+		dep := modDependency(mod, f.target())
+		if dep == nil {
+			continue
+		}
+		// The workspace uses the local copy of the dependency.
+		if dir, ok := modDirs[dep.Path]; ok {
+			if dir != "." {
+				dir = "./" + dir
+			}
+			dep.Version = strings.TrimSpace(dep.Version + " => " + dir)
+			dep.Replaced = true
+			dep.Via = viaGoWork
+		}
+		dep.Repo = repo.GetFullName()
+		deps = append(deps, dep)
+	}
+
+	if dep := work.replaceDependency(f.target()); dep != nil {
+		dep.Repo = repo.GetFullName()
+		dep.Module = fmt.Sprintf("github.com/%s", repo.GetFullName())
+		deps = append(deps, dep)
+	}
+
+	return deps, nil
 }
 
-func (f *finder) getFileContents(ctx context.Context, repo *github.Repository, filename string) ([]byte, error) {
-	fileContents, _, resp, err := f.gh.Repositories.GetContents(ctx, f.config.owner, repo.GetName(), filename, nil)
+// target returns the matcher of the module/package path.
+func (f *finder) target() target {
+	return target{path: f.config.modpath, exact: f.config.exact}
+}
+
+// print writes the dependency to stdout in the configured format.
+func (f *finder) print(dep *dependency) error {
+	switch f.config.format {
+	case formatRepos:
+		_, err := fmt.Fprintln(f.stdout, dep.Repo, dep.URL)
+		return err
+	case formatJSON:
+		encoder := json.NewEncoder(f.stdout)
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(dep)
+	default:
+		line := dep.Module
+		if dep.Version != "" {
+			line += " " + dep.Version
+		}
+		if !dep.Direct {
+			line += " // indirect"
+		}
+		if dep.Latest != "" {
+			line += " (latest " + dep.Latest + ")"
+		}
+		_, err := fmt.Fprintln(f.stdout, line)
+		return err
+	}
+}
+
+func (f *finder) getFileContents(ctx context.Context, repo *github.Repository, branch, filename string) ([]byte, error) {
+	opts := &github.RepositoryContentGetOptions{Ref: branch}
+	fileContents, _, resp, err := f.gh.Repositories.GetContents(ctx, repo.GetOwner().GetLogin(), repo.GetName(), filename, opts)
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return nil, nil
 		}
 		return nil, err
@@ -270,22 +618,46 @@ func (f *finder) getFileContents(ctx context.Context, repo *github.Repository, f
 	return []byte(contents), nil
 }
 
-func (f *finder) goRepo(ctx context.Context, repo *github.Repository) (bool, error) {
-	tree, resp, err := f.gh.Git.GetTree(ctx, f.config.owner, *repo.Name, "master", true)
+// repoTree holds paths of the repository tree.
+type repoTree struct {
+	paths     map[string]bool
+	goFiles   []*github.TreeEntry // .go files.
+	goRepo    bool                // The repository contains Go code or dependency manager files.
+	truncated bool                // The tree is too large to be listed in full.
+}
+
+// has reports whether the tree may contain the path.
+func (t *repoTree) has(path string) bool {
+	return t.truncated || t.paths[path]
+}
+
+// getTree returns the tree of the repository or nil if the repository is empty.
+func (f *finder) getTree(ctx context.Context, repo *github.Repository, branch string) (*repoTree, error) {
+	tree, resp, err := f.gh.Git.GetTree(ctx, repo.GetOwner().GetLogin(), repo.GetName(), branch, true)
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusConflict {
-			return false, nil
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusConflict) {
+			return nil, nil
 		}
-		return false, err
+		return nil, err
 	}
 
+	var (
+		goRepo  bool
+		goFiles []*github.TreeEntry
+		paths   = make(map[string]bool, len(tree.Entries))
+	)
 	for _, entry := range tree.Entries {
+		paths[entry.GetPath()] = true
+		if entry.GetType() == "blob" && strings.HasSuffix(entry.GetPath(), ".go") {
+			goFiles = append(goFiles, entry)
+		}
 		if strings.HasSuffix(entry.GetPath(), ".go") ||
 			strings.HasSuffix(entry.GetPath(), "Gopkg.toml") ||
-			strings.HasSuffix(entry.GetPath(), "go.mod") {
-			return true, nil
+			strings.HasSuffix(entry.GetPath(), "go.mod") ||
+			strings.HasSuffix(entry.GetPath(), "go.work") {
+			goRepo = true
 		}
 	}
 
-	return false, nil
+	return &repoTree{paths: paths, goFiles: goFiles, goRepo: goRepo, truncated: tree.GetTruncated()}, nil
 }
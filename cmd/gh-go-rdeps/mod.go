@@ -0,0 +1,111 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// dependency represents a repository that depends on the module.
+type dependency struct {
+	Repo     string `json:"repo"`             // The full repository name owner/repo.
+	Module   string `json:"module"`           // The path of the dependent module.
+	Path     string `json:"dependencyPath"`   // The path of the dependency as required.
+	Version  string `json:"version"`          // The required version or constraint.
+	Via      string `json:"via"`              // The dependency manager.
+	Direct   bool   `json:"direct"`           // Not an indirect requirement.
+	Replaced bool   `json:"replaced"`         // Replaced by a replace directive or a workspace module.
+	Latest   string `json:"latest,omitempty"` // The latest version if checked.
+	URL      string `json:"url"`              // The HTML URL of the repository.
+}
+
+// Dependency managers.
+const (
+	viaGoMod     = "gomod"
+	viaGoWork    = "gowork"
+	viaDep       = "dep"
+	viaVendor    = "vendor"
+	viaImports   = "imports"
+	viaNPM       = "npm"
+	viaPip       = "pip"
+	viaPyProject = "pyproject"
+)
+
+// target matches dependency paths against the module/package path.
+type target struct {
+	path  string
+	exact bool // Match the path exactly.
+}
+
+// gopkgMajor matches the gopkg.in major version suffix e.g. gopkg.in/yaml.v2.
+var gopkgMajor = regexp.MustCompile(`^\.v[0-9]+(/|$)`)
+
+// match reports whether the path is the target path or, unless exact,
+// a path within it i.e. the prefix ends at a path separator
+// or a major version suffix.
+func (t target) match(path string) bool {
+	if t.path == "" || !strings.HasPrefix(path, t.path) {
+		return false
+	}
+	rest := path[len(t.path):]
+	if rest == "" {
+		return true
+	}
+	if t.exact {
+		return false
+	}
+
+	return strings.HasPrefix(rest, "/") || strings.HasSuffix(t.path, "/") || gopkgMajor.MatchString(rest)
+}
+
+// modDependency looks up the module path in requirements and replacements
+// of the go.mod file. It returns nil if the module doesn't depend on the path.
+func modDependency(mod *modfile.File, target target) *dependency {
+	var dep *dependency
+	for _, require := range mod.Require {
+		if target.match(require.Mod.Path) {
+			dep = &dependency{
+				Path:    require.Mod.Path,
+				Version: require.Mod.Version,
+				Direct:  !require.Indirect,
+			}
+			break
+		}
+	}
+
+	for _, replace := range mod.Replace {
+		if !target.match(replace.Old.Path) && !target.match(replace.New.Path) {
+			continue
+		}
+		if dep != nil && replace.Old.Path != dep.Path {
+			continue // Replaces another module.
+		}
+		if dep == nil {
+			dep = &dependency{Path: replace.Old.Path}
+		}
+		dep.Version = strings.TrimSpace(dep.Version + " => " + replacement(replace.New))
+		dep.Replaced = true
+		break
+	}
+
+	if dep == nil {
+		return nil
+	}
+	if mod.Module != nil {
+		dep.Module = mod.Module.Mod.Path
+	}
+	dep.Via = viaGoMod
+
+	return dep
+}
+
+// replacement formats the replacement module as it appears in go.mod.
+func replacement(v module.Version) string {
+	if v.Version == "" {
+		return v.Path // A local directory.
+	}
+
+	return v.Path + " " + v.Version
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/mod/modfile"
+)
+
+func TestModDependency(t *testing.T) {
+	tests := []struct {
+		desc  string
+		gomod string
+		want  *dependency
+	}{
+		{
+			desc:  "no dependency",
+			gomod: "module example.com/app\n\nrequire github.com/foo/baz v1.0.0\n",
+		},
+		{
+			desc:  "require",
+			gomod: "module example.com/app\n\nrequire github.com/foo/bar v1.2.3\n",
+			want:  &dependency{Module: "example.com/app", Path: "github.com/foo/bar", Version: "v1.2.3", Via: viaGoMod, Direct: true},
+		},
+		{
+			desc: "require and replace",
+			gomod: "module example.com/app\n\nrequire github.com/foo/bar v1.2.3\n\n" +
+				"replace github.com/foo/bar => github.com/fork/bar v1.2.4\n",
+			want: &dependency{Module: "example.com/app", Path: "github.com/foo/bar", Version: "v1.2.3 => github.com/fork/bar v1.2.4", Via: viaGoMod, Direct: true, Replaced: true},
+		},
+		{
+			desc:  "replace only",
+			gomod: "module example.com/app\n\nreplace github.com/foo/bar => ../bar\n",
+			want:  &dependency{Module: "example.com/app", Path: "github.com/foo/bar", Version: "=> ../bar", Via: viaGoMod, Replaced: true},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mod, err := modfile.Parse("go.mod", []byte(tt.gomod), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tt.want, modDependency(mod, target{path: "github.com/foo/bar"}); !reflect.DeepEqual(want, got) {
+				t.Errorf("Expected %+v got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestTargetMatch(t *testing.T) {
+	tests := []struct {
+		target target
+		path   string
+		want   bool
+	}{
+		{target{path: "github.com/acme/lib"}, "github.com/acme/lib", true},
+		{target{path: "github.com/acme/lib"}, "github.com/acme/lib/v2", true},
+		{target{path: "github.com/acme/lib"}, "github.com/acme/lib/client", true},
+		{target{path: "github.com/acme/lib"}, "github.com/acme/lib-v2-fork", false},
+		{target{path: "github.com/acme/lib"}, "github.com/acme/li", false},
+		{target{path: "github.com/acme/"}, "github.com/acme/lib", true},
+		{target{path: "gopkg.in/yaml"}, "gopkg.in/yaml.v2", true},
+		{target{path: "gopkg.in/yaml"}, "gopkg.in/yaml.v2/sub", true},
+		{target{path: "gopkg.in/yaml"}, "gopkg.in/yamlx", false},
+		{target{path: "github.com/acme/lib", exact: true}, "github.com/acme/lib", true},
+		{target{path: "github.com/acme/lib", exact: true}, "github.com/acme/lib/v2", false},
+		{target{path: "github.com/acme/lib"}, "", false},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.want, tt.target.match(tt.path); want != got {
+			t.Errorf("%+v %s: expected %t got %t", tt.target, tt.path, want, got)
+		}
+	}
+}
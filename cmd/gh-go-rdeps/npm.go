@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// packageJSON represents a package.json file.
+type packageJSON struct {
+	Name                 string            `json:"name"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// packageLock represents a package-lock.json file.
+type packageLock struct {
+	// lockfileVersion 2 and later.
+	Packages map[string]struct {
+		Version string `json:"version"`
+	} `json:"packages"`
+	// lockfileVersion 1.
+	Dependencies map[string]struct {
+		Version string `json:"version"`
+	} `json:"dependencies"`
+}
+
+// jsDependencies looks up the package name in the package.json file
+// and, for transitive dependencies, in the package-lock.json file.
+func (f *finder) jsDependencies(ctx context.Context, repo *github.Repository, branch string, tree *repoTree) ([]*dependency, error) {
+	if !tree.has("package.json") {
+		return nil, nil
+	}
+
+	contents, err := f.getFileContents(ctx, repo, branch, "package.json")
+	if err != nil {
+		return nil, err
+	}
+	if len(contents) == 0 {
+		return nil, nil
+	}
+
+	pkg := &packageJSON{}
+	if err = json.Unmarshal(contents, pkg); err != nil {
+		return nil, fmt.Errorf("%s: package.json: %w", repo.GetFullName(), err)
+	}
+
+	dep := npmDependency(pkg, f.target())
+	if dep == nil && tree.has("package-lock.json") {
+		contents, err = f.getFileContents(ctx, repo, branch, "package-lock.json")
+		if err != nil {
+			return nil, err
+		}
+
+		if len(contents) > 0 {
+			lock := &packageLock{}
+			if err = json.Unmarshal(contents, lock); err != nil {
+				return nil, fmt.Errorf("%s: package-lock.json: %w", repo.GetFullName(), err)
+			}
+			dep = npmLockDependency(lock, f.target())
+		}
+	}
+
+	if dep == nil {
+		return nil, nil
+	}
+	dep.Repo = repo.GetFullName()
+	dep.Module = pkg.Name
+	if dep.Module == "" {
+		dep.Module = fmt.Sprintf("github.com/%s", repo.GetFullName())
+	}
+
+	return []*dependency{dep}, nil
+}
+
+// npmDependency looks up the package name in dependencies of the package.json file.
+// It returns nil if there is no dependency on the package.
+func npmDependency(pkg *packageJSON, target target) *dependency {
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.PeerDependencies, pkg.OptionalDependencies} {
+		if name := matchName(deps, target); name != "" {
+			return &dependency{
+				Path:    name,
+				Version: deps[name],
+				Via:     viaNPM,
+				Direct:  true,
+			}
+		}
+	}
+
+	return nil
+}
+
+// npmLockDependency looks up the package name in packages of the package-lock.json file.
+// It returns nil if there is no dependency on the package.
+func npmLockDependency(lock *packageLock, target target) *dependency {
+	versions := make(map[string]string, len(lock.Packages)+len(lock.Dependencies))
+	for path, pkg := range lock.Packages {
+		i := strings.LastIndex(path, "node_modules/")
+		if i < 0 {
+			continue // The root package.
+		}
+		name := path[i+len("node_modules/"):]
+		if _, ok := versions[name]; !ok || !strings.Contains(path[:i], "node_modules/") {
+			versions[name] = pkg.Version // Prefer the top level package.
+		}
+	}
+	for name, pkg := range lock.Dependencies {
+		if _, ok := versions[name]; !ok {
+			versions[name] = pkg.Version
+		}
+	}
+
+	name := matchName(versions, target)
+	if name == "" {
+		return nil
+	}
+
+	return &dependency{
+		Path:    name,
+		Version: versions[name],
+		Via:     viaNPM,
+	}
+}
+
+// matchName returns the first, in the lexical order, name
+// matching the target. It returns an empty string if there is no match.
+func matchName(deps map[string]string, target target) string {
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		if target.match(name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+
+	return names[0]
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestNPMDependency(t *testing.T) {
+	pkg := &packageJSON{}
+	err := json.Unmarshal([]byte(`{
+  "name": "app",
+  "dependencies": {"lodash": "^4.17.21", "@acme/ui": "1.2.0"},
+  "devDependencies": {"@acme/lint": "~2.0.0"}
+}`), pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		target target
+		want   *dependency
+	}{
+		{target: target{path: "lodash"}, want: &dependency{Path: "lodash", Version: "^4.17.21", Via: viaNPM, Direct: true}},
+		{target: target{path: "@acme/lint"}, want: &dependency{Path: "@acme/lint", Version: "~2.0.0", Via: viaNPM, Direct: true}},
+		{target: target{path: "@acme/"}, want: &dependency{Path: "@acme/ui", Version: "1.2.0", Via: viaNPM, Direct: true}},
+		{target: target{path: "lodash.merge"}},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.want, npmDependency(pkg, tt.target); !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: expected %+v got %+v", tt.target.path, want, got)
+		}
+	}
+}
+
+func TestNPMLockDependency(t *testing.T) {
+	tests := []struct {
+		desc string
+		lock string
+		want *dependency
+	}{
+		{
+			desc: "packages",
+			lock: `{"lockfileVersion": 2, "packages": {
+  "": {"name": "app"},
+  "node_modules/a/node_modules/left-pad": {"version": "1.0.0"},
+  "node_modules/left-pad": {"version": "1.3.0"}
+}}`,
+			want: &dependency{Path: "left-pad", Version: "1.3.0", Via: viaNPM},
+		},
+		{
+			desc: "dependencies",
+			lock: `{"lockfileVersion": 1, "dependencies": {"left-pad": {"version": "1.1.0"}}}`,
+			want: &dependency{Path: "left-pad", Version: "1.1.0", Via: viaNPM},
+		},
+		{
+			desc: "no dependency",
+			lock: `{"lockfileVersion": 1, "dependencies": {"right-pad": {"version": "1.1.0"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		lock := &packageLock{}
+		if err := json.Unmarshal([]byte(tt.lock), lock); err != nil {
+			t.Fatal(err)
+		}
+		if want, got := tt.want, npmLockDependency(lock, target{path: "left-pad"}); !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: expected %+v got %+v", tt.desc, want, got)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/pelletier/go-toml"
+)
+
+// pyProject represents a pyproject.toml file.
+type pyProject struct {
+	Project struct {
+		Name                 string              `toml:"name"`
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+	Tool struct {
+		Poetry struct {
+			Name            string                 `toml:"name"`
+			Dependencies    map[string]interface{} `toml:"dependencies"`
+			DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+// pythonDependencies looks up the package name in the pyproject.toml
+// and requirements.txt files.
+func (f *finder) pythonDependencies(ctx context.Context, repo *github.Repository, branch string, tree *repoTree) ([]*dependency, error) {
+	var (
+		project *pyProject
+		dep     *dependency
+	)
+
+	if tree.has("pyproject.toml") {
+		contents, err := f.getFileContents(ctx, repo, branch, "pyproject.toml")
+		if err != nil {
+			return nil, err
+		}
+
+		if len(contents) > 0 {
+			project = &pyProject{}
+			if err = toml.Unmarshal(contents, project); err != nil {
+				return nil, fmt.Errorf("%s: pyproject.toml: %w", repo.GetFullName(), err)
+			}
+			dep = pyProjectDependency(project, f.target())
+		}
+	}
+
+	if dep == nil && tree.has("requirements.txt") {
+		contents, err := f.getFileContents(ctx, repo, branch, "requirements.txt")
+		if err != nil {
+			return nil, err
+		}
+
+		dep = requirementsDependency(contents, f.target())
+	}
+
+	if dep == nil {
+		return nil, nil
+	}
+	dep.Repo = repo.GetFullName()
+	dep.Module = fmt.Sprintf("github.com/%s", repo.GetFullName())
+	if project != nil && project.Project.Name != "" {
+		dep.Module = project.Project.Name
+	} else if project != nil && project.Tool.Poetry.Name != "" {
+		dep.Module = project.Tool.Poetry.Name
+	}
+
+	return []*dependency{dep}, nil
+}
+
+// pyProjectDependency looks up the package name in PEP 621 and Poetry
+// dependencies of the pyproject.toml file. It returns nil if there is no dependency on the package.
+func pyProjectDependency(project *pyProject, target target) *dependency {
+	requirements := project.Project.Dependencies
+	groups := make([]string, 0, len(project.Project.OptionalDependencies))
+	for group := range project.Project.OptionalDependencies {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		requirements = append(requirements, project.Project.OptionalDependencies[group]...)
+	}
+
+	for _, requirement := range requirements {
+		if name, spec, ok := parseRequirement(requirement); ok && matchPyName(name, target) {
+			return &dependency{Path: name, Version: spec, Via: viaPyProject, Direct: true}
+		}
+	}
+
+	for _, deps := range []map[string]interface{}{project.Tool.Poetry.Dependencies, project.Tool.Poetry.DevDependencies} {
+		names := make([]string, 0, len(deps))
+		for name := range deps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if !matchPyName(name, target) {
+				continue
+			}
+
+			var version string
+			switch v := deps[name].(type) {
+			case string:
+				version = v
+			case map[string]interface{}:
+				version, _ = v["version"].(string)
+			}
+
+			return &dependency{Path: name, Version: version, Via: viaPyProject, Direct: true}
+		}
+	}
+
+	return nil
+}
+
+// requirementsDependency looks up the package name in the requirements.txt file.
+// It returns nil if there is no dependency on the package.
+func requirementsDependency(requirements []byte, target target) *dependency {
+	scanner := bufio.NewScanner(bytes.NewReader(requirements))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		// Comments and options e.g. -r other.txt.
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+
+		if name, spec, ok := parseRequirement(line); ok && matchPyName(name, target) {
+			return &dependency{Path: name, Version: spec, Via: viaPip, Direct: true}
+		}
+	}
+
+	return nil
+}
+
+var requirementRegexp = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(\[[^\]]*\])?\s*(.*)$`)
+
+// parseRequirement parses the PEP 508 requirement e.g. requests[security]>=2.8.1; python_version < "3.8"
+// into the name and the version specifier.
+func parseRequirement(requirement string) (name, spec string, ok bool) {
+	if i := strings.Index(requirement, ";"); i >= 0 {
+		requirement = requirement[:i] // Environment markers.
+	}
+
+	matches := requirementRegexp.FindStringSubmatch(strings.TrimSpace(requirement))
+	if matches == nil {
+		return "", "", false
+	}
+	spec = strings.TrimSpace(matches[3])
+	spec = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(spec, "("), ")"))
+
+	return matches[1], spec, true
+}
+
+var pyNameRegexp = regexp.MustCompile(`[-_.]+`)
+
+// matchPyName reports whether the package name matches the target.
+// Names are compared in the PEP 503 normalized form.
+func matchPyName(name string, target target) bool {
+	target.path = pyNameRegexp.ReplaceAllString(strings.ToLower(target.path), "-")
+
+	return target.match(pyNameRegexp.ReplaceAllString(strings.ToLower(name), "-"))
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pelletier/go-toml"
+)
+
+func TestRequirementsDependency(t *testing.T) {
+	requirements := []byte(`# Application requirements.
+-r base.txt
+requests-oauthlib==1.3.0
+Requests[security] >= 2.8.1, < 3 ; python_version < "3.8" # HTTP
+flask
+`)
+	tests := []struct {
+		name string
+		want *dependency
+	}{
+		{"requests", &dependency{Path: "Requests", Version: ">= 2.8.1, < 3", Via: viaPip, Direct: true}},
+		{"requests_oauthlib", &dependency{Path: "requests-oauthlib", Version: "==1.3.0", Via: viaPip, Direct: true}},
+		{"Flask", &dependency{Path: "flask", Via: viaPip, Direct: true}},
+		{"django", nil},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.want, requirementsDependency(requirements, target{path: tt.name}); !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: expected %+v got %+v", tt.name, want, got)
+		}
+	}
+}
+
+func TestPyProjectDependency(t *testing.T) {
+	project := &pyProject{}
+	err := toml.Unmarshal([]byte(`
+[project]
+name = "app"
+dependencies = ["httpx>=0.23", "attrs"]
+
+[project.optional-dependencies]
+test = ["pytest (>=7.0)"]
+
+[tool.poetry]
+name = "app"
+
+[tool.poetry.dependencies]
+python = "^3.9"
+pydantic = { version = "^1.10", extras = ["email"] }
+
+[tool.poetry.dev-dependencies]
+black = "^22.3"
+`), project)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		want *dependency
+	}{
+		{"httpx", &dependency{Path: "httpx", Version: ">=0.23", Via: viaPyProject, Direct: true}},
+		{"pytest", &dependency{Path: "pytest", Version: ">=7.0", Via: viaPyProject, Direct: true}},
+		{"pydantic", &dependency{Path: "pydantic", Version: "^1.10", Via: viaPyProject, Direct: true}},
+		{"black", &dependency{Path: "black", Version: "^22.3", Via: viaPyProject, Direct: true}},
+		{"django", nil},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.want, pyProjectDependency(project, target{path: tt.name}); !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: expected %+v got %+v", tt.name, want, got)
+		}
+	}
+}
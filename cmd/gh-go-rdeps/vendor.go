@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// vendorDependency looks up the module path in the vendor/modules.txt file.
+// It returns nil if there is no vendored module matching the path.
+func vendorDependency(modules []byte, target target) *dependency {
+	var dep *dependency
+	scanner := bufio.NewScanner(bytes.NewReader(modules))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "## "):
+			// Module annotations e.g. ## explicit; go 1.17.
+			if dep != nil {
+				dep.Direct = strings.HasPrefix(line, "## explicit")
+				return dep
+			}
+		case strings.HasPrefix(line, "# "):
+			if dep != nil {
+				return dep
+			}
+			fields := strings.Fields(line[2:])
+			if len(fields) == 0 || !target.match(fields[0]) {
+				continue
+			}
+			dep = &dependency{
+				Path:    fields[0],
+				Version: strings.Join(fields[1:], " "),
+				Via:     viaVendor,
+			}
+			dep.Replaced = strings.Contains(dep.Version, "=>")
+		default:
+			// Package paths.
+			if dep != nil {
+				return dep
+			}
+		}
+	}
+
+	return dep
+}
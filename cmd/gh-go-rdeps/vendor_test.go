@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVendorDependency(t *testing.T) {
+	modules := `# github.com/foo/baz v1.0.0
+## explicit
+github.com/foo/baz
+# github.com/foo/bar v1.2.3 => github.com/fork/bar v1.2.4
+github.com/foo/bar
+github.com/foo/bar/sub
+# github.com/foo/qux v0.1.0
+## explicit; go 1.16
+github.com/foo/qux
+`
+	tests := []struct {
+		modpath string
+		want    *dependency
+	}{
+		{modpath: "github.com/foo/quux"},
+		{
+			modpath: "github.com/foo/bar",
+			want:    &dependency{Path: "github.com/foo/bar", Version: "v1.2.3 => github.com/fork/bar v1.2.4", Via: viaVendor, Replaced: true},
+		},
+		{
+			modpath: "github.com/foo/qux",
+			want:    &dependency{Path: "github.com/foo/qux", Version: "v0.1.0", Via: viaVendor, Direct: true},
+		},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.want, vendorDependency([]byte(modules), target{path: tt.modpath}); !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: expected %+v got %+v", tt.modpath, want, got)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// workFile represents a go.work file.
+type workFile struct {
+	Use     []string           // Module directories.
+	Replace []*modfile.Replace // Workspace wide replacements.
+}
+
+// parseWork parses a go.work file. go.work shares the syntax with go.mod
+// so the file is parsed leniently and use and replace directives are
+// picked up from the syntax tree.
+func parseWork(data []byte) (*workFile, error) {
+	f, err := modfile.ParseLax("go.work", data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	work := &workFile{}
+	add := func(verb string, args []string) error {
+		for i := range args {
+			if args[i], err = unquote(args[i]); err != nil {
+				return err
+			}
+		}
+
+		switch verb {
+		case "use":
+			if len(args) != 1 {
+				return fmt.Errorf("go.work: usage: use local/dir")
+			}
+			work.Use = append(work.Use, args[0])
+		case "replace":
+			replace, err := parseReplace(args)
+			if err != nil {
+				return err
+			}
+			work.Replace = append(work.Replace, replace)
+		}
+
+		return nil
+	}
+
+	for _, stmt := range f.Syntax.Stmt {
+		switch x := stmt.(type) {
+		case *modfile.Line:
+			if len(x.Token) == 0 {
+				continue
+			}
+			if err = add(x.Token[0], x.Token[1:]); err != nil {
+				return nil, err
+			}
+		case *modfile.LineBlock:
+			if len(x.Token) != 1 {
+				continue
+			}
+			for _, line := range x.Line {
+				if err = add(x.Token[0], line.Token); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return work, nil
+}
+
+// parseReplace parses replace directive arguments
+// old [version] => new [version].
+func parseReplace(args []string) (*modfile.Replace, error) {
+	arrow := 2
+	if len(args) >= 2 && args[1] == "=>" {
+		arrow = 1
+	}
+	if len(args) < arrow+2 || len(args) > arrow+3 || args[arrow] != "=>" {
+		return nil, fmt.Errorf("go.work: usage: replace module/path [v1.2.3] => other/module v1.4 or replace module/path [v1.2.3] => ../local/directory")
+	}
+
+	replace := &modfile.Replace{Old: module.Version{Path: args[0]}, New: module.Version{Path: args[arrow+1]}}
+	if arrow == 2 {
+		replace.Old.Version = args[1]
+	}
+	if len(args) == arrow+3 {
+		replace.New.Version = args[arrow+2]
+	}
+
+	return replace, nil
+}
+
+// unquote unquotes a go.work token if it's quoted.
+func unquote(s string) (string, error) {
+	if !strings.HasPrefix(s, `"`) && !strings.HasPrefix(s, "`") {
+		return s, nil
+	}
+
+	return strconv.Unquote(s)
+}
+
+// replaceDependency looks up the module path in the workspace replacements.
+// It returns nil if the workspace doesn't replace the path.
+func (w *workFile) replaceDependency(target target) *dependency {
+	dep := modDependency(&modfile.File{Replace: w.Replace}, target)
+	if dep == nil {
+		return nil
+	}
+	dep.Via = viaGoWork
+
+	return dep
+}
+
+// localUse returns the workspace module directories
+// that are within the repository.
+func (w *workFile) localUse() []string {
+	var dirs []string
+	for _, dir := range w.Use {
+		dir = path.Clean(dir)
+		if path.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, "../") {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+
+	return dirs
+}
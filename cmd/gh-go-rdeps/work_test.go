@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWork(t *testing.T) {
+	gowork := `go 1.18
+
+use (
+	.
+	./tools
+	"./cmd/app"
+	../shared
+)
+
+use ./lib
+
+replace github.com/foo/bar v1.2.3 => ../bar
+
+replace (
+	github.com/foo/baz => github.com/fork/baz v1.0.1
+)
+`
+	work, err := parseWork([]byte(gowork))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := []string{".", "./tools", "./cmd/app", "../shared", "./lib"}, work.Use; !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected use %v got %v", want, got)
+	}
+	if want, got := []string{".", "tools", "cmd/app", "lib"}, work.localUse(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected local use %v got %v", want, got)
+	}
+
+	if want, got := 2, len(work.Replace); want != got {
+		t.Fatalf("Expected %d replacements got %d", want, got)
+	}
+	if want, got := "github.com/foo/bar v1.2.3 => ../bar", work.Replace[0].Old.Path+" "+work.Replace[0].Old.Version+" => "+replacement(work.Replace[0].New); want != got {
+		t.Errorf("Expected %s got %s", want, got)
+	}
+	if want, got := "github.com/foo/baz => github.com/fork/baz v1.0.1", work.Replace[1].Old.Path+" => "+replacement(work.Replace[1].New); want != got {
+		t.Errorf("Expected %s got %s", want, got)
+	}
+
+	want := &dependency{Path: "github.com/foo/bar", Version: "=> ../bar", Via: viaGoWork, Replaced: true}
+	if got := work.replaceDependency(target{path: "github.com/foo/bar"}); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected %+v got %+v", want, got)
+	}
+	if got := work.replaceDependency(target{path: "github.com/foo/qux"}); got != nil {
+		t.Errorf("Expected nil got %+v", got)
+	}
+}
+
+func TestParseWorkInvalid(t *testing.T) {
+	for _, gowork := range []string{
+		"use a b\n",
+		"replace github.com/foo/bar\n",
+		"replace github.com/foo/bar v1 v2 => ../bar\n",
+	} {
+		if _, err := parseWork([]byte(gowork)); err == nil {
+			t.Errorf("Expected an error for %q", gowork)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// codeownersPaths are the locations GitHub looks up the CODEOWNERS file at.
+var codeownersPaths = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// codeownersRule represents a single line of the CODEOWNERS file.
+type codeownersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// codeowners represents a parsed CODEOWNERS file.
+type codeowners []codeownersRule
+
+// parseCodeowners parses the contents of the CODEOWNERS file.
+// Lines with invalid patterns are ignored.
+func parseCodeowners(contents string) codeowners {
+	var rules codeowners
+
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		pattern, err := codeownersPattern(fields[0])
+		if err != nil {
+			continue
+		}
+		rules = append(rules, codeownersRule{pattern: pattern, owners: fields[1:]})
+	}
+
+	return rules
+}
+
+// codeownersPattern converts a CODEOWNERS gitignore-style pattern to a regexp.
+func codeownersPattern(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	// Patterns starting with or containing a slash are relative to the root.
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+				continue
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	switch {
+	case dirOnly:
+		b.WriteString("/.*$")
+	case strings.ContainsAny(pattern[strings.LastIndex(pattern, "/")+1:], "*?"):
+		// Wildcards in the last component don't match nested paths e.g.
+		// docs/* matches docs/index.md but not docs/build/index.md.
+		b.WriteString("$")
+	default:
+		// The pattern matches the path itself or everything under it.
+		b.WriteString("(?:/.*)?$")
+	}
+
+	return regexp.Compile(b.String())
+}
+
+// owners returns the owners of the path. The last matching rule takes precedence.
+func (c codeowners) owners(path string) []string {
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].pattern.MatchString(path) {
+			return c[i].owners
+		}
+	}
+
+	return nil
+}
+
+// reviewers returns unique user and team reviewers that own any of the paths.
+// Team owners @org/team are returned as team slugs. Email owners are ignored.
+func (c codeowners) reviewers(paths []string) (users, teams []string) {
+	seen := map[string]struct{}{}
+	for _, path := range paths {
+		for _, owner := range c.owners(path) {
+			if !strings.HasPrefix(owner, "@") {
+				continue // Email.
+			}
+			owner = strings.ToLower(owner[1:])
+			if _, ok := seen[owner]; ok {
+				continue
+			}
+			seen[owner] = struct{}{}
+
+			if i := strings.Index(owner, "/"); i >= 0 {
+				teams = append(teams, owner[i+1:])
+			} else {
+				users = append(users, owner)
+			}
+		}
+	}
+
+	return users, teams
+}
+
+// getCodeowners reads and parses the CODEOWNERS file in the default branch
+// of the repository. It returns nil if there is none.
+func (p *prmaker) getCodeowners(ctx context.Context, repo *github.Repository) (codeowners, error) {
+	opts := &github.RepositoryContentGetOptions{Ref: repo.GetDefaultBranch()}
+	for _, path := range codeownersPaths {
+		fileContents, _, resp, err := p.gh.Repositories.GetContents(ctx, repo.GetOwner().GetLogin(), repo.GetName(), path, opts)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return nil, err
+		}
+		if fileContents == nil { // It's a directory.
+			continue
+		}
+
+		contents, err := fileContents.GetContent()
+		if err != nil {
+			return nil, err
+		}
+
+		return parseCodeowners(contents), nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodeownersOwners(t *testing.T) {
+	c := parseCodeowners(`
+# Default owners.
+*       @global-owner
+
+*.js    @js-owner # Inline comment.
+/build/logs/ @logs-owner
+docs/*  docs@example.com
+apps/   @octocat
+/scripts/ @doctocat @octocat
+**/deploy @deploy-owner
+/api/**/handlers @org/api-team
+`)
+
+	tests := []struct {
+		path   string
+		owners []string
+	}{
+		{"README.md", []string{"@global-owner"}},
+		{"src/index.js", []string{"@js-owner"}},
+		{"build/logs/app.log", []string{"@logs-owner"}},
+		{"src/build/logs/app.log", []string{"@global-owner"}},
+		{"docs/getting-started.md", []string{"docs@example.com"}},
+		{"docs/build-app/troubleshooting.md", []string{"@global-owner"}},
+		{"apps/web/main.go", []string{"@octocat"}},
+		{"src/apps/web/main.go", []string{"@octocat"}},
+		{"scripts/build.sh", []string{"@doctocat", "@octocat"}},
+		{"deploy/prod.yml", []string{"@deploy-owner"}},
+		{"k8s/deploy/prod.yml", []string{"@deploy-owner"}},
+		{"api/handlers/user.go", []string{"@org/api-team"}},
+		{"api/v1/handlers/user.go", []string{"@org/api-team"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+			if want, got := tt.owners, c.owners(tt.path); !reflect.DeepEqual(want, got) {
+				t.Errorf("Expected %v got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestCodeownersReviewers(t *testing.T) {
+	c := parseCodeowners(`
+*        @Global-Owner
+*.go     @gopher @org/Go-Team
+docs/    docs@example.com
+`)
+
+	users, teams := c.reviewers([]string{"README.md", "main.go", "cmd/main.go", "docs/index.md"})
+	if want, got := []string{"global-owner", "gopher"}, users; !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected users %v got %v", want, got)
+	}
+	if want, got := []string{"go-team"}, teams; !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected teams %v got %v", want, got)
+	}
+}
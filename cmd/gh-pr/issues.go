@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// findIssuesToClose returns numbers of open issues in the repository
+// which titles match the closes-issue pattern.
+func (p *prmaker) findIssuesToClose(ctx context.Context, repo *github.Repository) ([]int, error) {
+	if p.config.closesIssueRegexp == nil {
+		return nil, nil // There is nothing to do.
+	}
+
+	var (
+		numbers []int
+		issues  []*github.Issue
+		resp    *github.Response
+		err     error
+		opts    = &github.IssueListByRepoOptions{
+			State:       "open",
+			ListOptions: github.ListOptions{PerPage: 100},
+		}
+	)
+	for {
+		issues, resp, err = p.gh.Issues.ListByRepo(ctx, repo.GetOwner().GetLogin(), repo.GetName(), opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: can't read issues: %s", repo.GetFullName(), err)
+		}
+
+		for _, issue := range issues {
+			if issue.IsPullRequest() {
+				continue
+			}
+			if p.config.closesIssueRegexp.MatchString(issue.GetTitle()) {
+				numbers = append(numbers, issue.GetNumber())
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return numbers, nil
+}
+
+// appendClosesIssues appends "Closes #N" lines to the PR description.
+func appendClosesIssues(body string, numbers []int) string {
+	if len(numbers) == 0 {
+		return body
+	}
+
+	lines := make([]string, len(numbers))
+	for i, number := range numbers {
+		lines[i] = fmt.Sprintf("Closes #%d", number)
+	}
+
+	if body == "" {
+		return strings.Join(lines, "\n")
+	}
+
+	return strings.TrimRight(body, "\n") + "\n\n" + strings.Join(lines, "\n")
+}
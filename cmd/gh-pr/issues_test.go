@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAppendClosesIssues(t *testing.T) {
+	tests := []struct {
+		body    string
+		numbers []int
+		out     string
+	}{
+		{"Fix", nil, "Fix"},
+		{"", []int{1}, "Closes #1"},
+		{"Fix", []int{1}, "Fix\n\nCloses #1"},
+		{"Fix\n", []int{1, 23}, "Fix\n\nCloses #1\nCloses #23"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if want, got := tt.out, appendClosesIssues(tt.body, tt.numbers); want != got {
+				t.Errorf("Expected %q got %q", want, got)
+			}
+		})
+	}
+}
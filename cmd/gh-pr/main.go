@@ -10,14 +10,18 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/go-git/go-git/v5"
 	gitConfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	gitHTTP "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitSSH "github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/google/go-github/v32/github"
 	"github.com/pmatseykanets/gh-tools/auth"
 	gh "github.com/pmatseykanets/gh-tools/github"
@@ -30,29 +34,78 @@ func usage() {
 	usage := `Automate PR creation across GitHub repositories
 
 Usage: gh-pr [flags] [owner][/repo]
+       gh-pr [flags] -repo-file=<path>
+       gh-pr -rollback -state-file=<path>
   owner         Repository owner (user or organization)
   repo          Repository name
 
 Flags:
-  -assign=          The GitHub user login to assign the PR to
-  -help, h          Print this information and exit
-  -branch=          The branch name if different from the default
-  -commit-message=  The commit message
-  -desc=            The PR description
-  -list             List PR associated with the branch
-  -no-fork          Don't include fork repositories
-  -no-private       Don't include private repositories
-  -no-public        Don't include public repositories
-  -no-repo=         The pattern to reject repository names
-  -patch            Apply changes to the existing PR
-  -repo=            The pattern to match repository names
-  -review=          The GitHub user login to request the PR review from
-  -script=          The script to apply changes
-  -script-file=     Read the script from a file
-  -shell=           The shell to use to run the script
-  -title=           The PR title
-  -token            Prompt for an Access Token
-  -version          Print the version and exit
+  -add-path=                  The glob pattern of paths to commit. Default all changes
+  -allow-empty                Create the PR with an empty commit if the script made no changes
+  -assign=                    The GitHub user login to assign the PR to
+  -help, h                    Print this information and exit
+  -branch=                    The branch name. Supports placeholders
+                                {date} - the current date YYYYMMDD
+                                {hash} - the short hash of the script
+  -branch-suffix-on-conflict  Create a uniquely suffixed branch if the branch already exists
+  -check-script=              The script to check if the repository should be changed.
+                                Non-zero exit code skips the repository
+  -check-script-file=         Read the check script from a file
+  -closes-issue-pattern=      The pattern to match titles of open issues to close with the PR
+  -comment=                   The comment to post on the existing PR instead of updating
+                                its title and description. Requires -patch. Not posted when
+                                the patch made no changes
+  -commit-message=            The commit message
+  -commit-message-file=       Read the commit message from a file
+  -default-branch=            Only include repositories with the default branch
+  -delete-branch-on-merge     Delete the branch once its PR is merged. Requires -list
+  -desc=                      The PR description. In -patch mode the PR description is only
+                                updated when set
+  -docker-image=              Run the script inside a Docker container using the image
+  -enable-delete-branch-on-merge
+                              Turn on automatic deletion of head branches after PRs are merged
+                                in repositories where PRs were created or patched.
+                                Changes the repository setting and requires admin permissions
+  -language=                  Only include repositories with the primary language
+  -list                       List PR associated with the branch
+  -max-repos=                 Limit the number of repositories to create or patch PRs in
+  -no-maintainer-edit         Don't allow maintainers to modify the PR
+  -no-fork                    Don't include fork repositories
+  -no-private                 Don't include private repositories
+  -no-public                  Don't include public repositories
+  -no-repo=                   The pattern to reject repository names
+  -patch                      Apply changes to the existing PR
+  -pause=                     Pause between creating or patching PRs (e.g. 3m)
+  -project=                   Add created or patched PRs to the project (v2) in the form owner/number
+  -repo=                      The pattern to match repository names
+  -repo-file=                 Read repository names (owner/repo) one per line from the file
+                                instead of searching for repositories. Use - for stdin.
+                                Repository filters (e.g. -repo, -no-fork) apply to the listed ones
+  -rollback                   Close PRs, remove requested reviews and delete branches
+                                recorded in the state file by a previous run
+  -review=                    The GitHub user login to request the PR review from
+  -review-codeowners          Request reviews from CODEOWNERS of the changed paths
+  -script=                    The script to apply changes
+  -script-file=               Read the script from a file
+  -shell=                     The shell to use to run the script
+  -sparse-path=               Make a partial clone and check out only the path.
+                                Requires git 2.31+
+  -state-file=                Record created PRs in the file to be able to roll them back
+  -ssh                        Clone and push over SSH using the SSH agent
+  -ssh-key=                   Clone and push over SSH using the private key file. Implies
+                                -ssh
+  -title=                     The PR title
+  -token                      Prompt for an Access Token
+  -topic=                     Only include repositories with the topic
+  -trailer=                   The trailer to append to the commit message
+                                in the form key:value (e.g. Signed-off-by:Jane <jane@example.com>)
+  -url-file=                  Append created PRs to the file in the form owner/repo URL
+  -use-pr-template            Use the repository PR template as the PR description.
+                                The description is injected in place of
+                                <!-- gh-pr:desc --> or prepended to the template
+  -verify=                    The command to verify changes before pushing (e.g. go test ./...).
+                                Non-zero exit code skips the repository
+  -version                    Print the version and exit
 `
 	fmt.Printf("gh-pr version %s\n", version.Version)
 	fmt.Println(usage)
@@ -66,32 +119,68 @@ func main() {
 }
 
 type config struct {
-	owner         string
-	repo          string
-	repoRegexp    *regexp.Regexp // The pattern to match respository names.
-	branch        string         // The branch name if different from the default.
-	desc          string         // The PR description.
-	reviewers     []string       // The GitHub user login to request the PR review from.
-	assignees     []string       // The GitHub user login to assign the PR to.
-	script        string         // The body of the script.
-	shell         string         // The shell to use to run the script.
-	title         string         // The PR title.
-	token         bool           // Propmt for an access token.
-	noPrivate     bool           // Don't include private repositories.
-	noPublic      bool           // Don't include public repositories.
-	noFork        bool           // Don't include fork repositories.
-	noRepoRegexp  *regexp.Regexp // The pattern to reject repository names.
-	patch         bool           // Apply changes to the existing PR
-	commitMessage string         // The commit message
-	list          bool           // List PR associated with the branch
+	owner              string
+	repo               string
+	repoRegexp         *regexp.Regexp // The pattern to match respository names.
+	branch             string         // The branch name if different from the default.
+	desc               string         // The PR description.
+	reviewers          []string       // The GitHub user login to request the PR review from.
+	assignees          []string       // The GitHub user login to assign the PR to.
+	script             string         // The body of the script.
+	shell              string         // The shell to use to run the script.
+	title              string         // The PR title.
+	token              bool           // Propmt for an access token.
+	noPrivate          bool           // Don't include private repositories.
+	noPublic           bool           // Don't include public repositories.
+	noFork             bool           // Don't include fork repositories.
+	noRepoRegexp       *regexp.Regexp // The pattern to reject repository names.
+	patch              bool           // Apply changes to the existing PR
+	commitMessage      string         // The commit message
+	list               bool           // List PR associated with the branch
+	dockerImage        string         // The Docker image to run the script in.
+	checkScript        string         // The body of the script to check if the repository should be changed.
+	addPaths           []string       // The glob patterns of paths to commit.
+	maxRepos           int            // Limit the number of repositories to create or patch PRs in.
+	pause              time.Duration  // Pause between creating or patching PRs.
+	ssh                bool           // Clone and push over SSH.
+	sshKey             string         // The private key file to use with SSH.
+	sparsePaths        []string       // The paths to check out in a partial clone.
+	usePRTemplate      bool           // Use the repository PR template as the PR description.
+	trailers           []string       // The trailers to append to the commit message.
+	closesIssueRegexp  *regexp.Regexp // The pattern to match titles of open issues to close with the PR.
+	allowEmpty         bool           // Create the PR with an empty commit if the script made no changes.
+	branchSuffix       bool           // Create a uniquely suffixed branch if the branch already exists.
+	noMaintainerEdit   bool           // Don't allow maintainers to modify the PR.
+	verify             string         // The command to verify changes before pushing.
+	defaultBranch      string         // Only include repositories with the default branch.
+	stateFile          string         // The file to record created PRs in.
+	rollback           bool           // Roll back PRs recorded in the state file.
+	reviewCodeowners   bool           // Request reviews from code owners of the changed paths.
+	comment            string         // The comment to post on the existing PR instead of updating it.
+	topics             []string       // Only include repositories with the topics.
+	languages          []string       // Only include repositories with the primary languages.
+	deleteBranch       bool           // Delete head branches of merged PRs when listing.
+	enableDeleteBranch bool           // Turn on automatic deletion of head branches after PRs are merged.
+	urlFile            string         // The file to append created PR URLs to.
+	repoNames          []string       // The full names of repositories to use instead of searching.
+	projectOwner       string         // The owner of the project (v2) to add PRs to.
+	projectNumber      int            // The number of the project (v2) to add PRs to.
 }
 
 type prmaker struct {
-	gh      *github.Client
-	ghToken string
-	config  config
-	stdout  io.WriteCloser
-	stderr  io.WriteCloser
+	gh               *github.Client
+	ghToken          string
+	gitAuth          transport.AuthMethod
+	config           config
+	scriptPath       string // The path to the script temp file.
+	checkScriptPath  string // The path to the check script temp file.
+	verifyScriptPath string // The path to the verification command temp file.
+	state            *runState
+	login            string // The authenticated user login.
+	projectID        string // The node ID of the project to add PRs to.
+	urlFile          io.WriteCloser
+	stdout           io.WriteCloser
+	stderr           io.WriteCloser
 }
 
 type stringList []string
@@ -119,29 +208,58 @@ func readConfig() (config, error) {
 	}
 
 	var (
-		showVersion, showHelp    bool
-		repo, noRepo, scriptFile string
-		review, assign           stringList
-		err                      error
+		showVersion, showHelp                                                                        bool
+		repo, noRepo, scriptFile, checkScriptFile, commitMessageFile, closesIssue, project, repoFile string
+		review, assign, addPath, sparsePath, trailer, topic, language                                stringList
+		err                                                                                          error
 	)
+	flag.Var(&addPath, "add-path", "The glob pattern of paths to commit")
+	flag.BoolVar(&config.allowEmpty, "allow-empty", config.allowEmpty, "Create the PR with an empty commit if the script made no changes")
 	flag.Var(&assign, "assign", "The GitHub user login to assign the PR to")
+	flag.StringVar(&closesIssue, "closes-issue-pattern", "", "The pattern to match titles of open issues to close with the PR")
+	flag.StringVar(&config.comment, "comment", "", "The comment to post on the existing PR instead of updating it")
 	flag.StringVar(&config.commitMessage, "commit-message", "", "The commit message")
+	flag.StringVar(&commitMessageFile, "commit-message-file", "", "Read the commit message from a file")
 	flag.StringVar(&config.branch, "branch", "", "The PR branch name")
+	flag.BoolVar(&config.branchSuffix, "branch-suffix-on-conflict", config.branchSuffix, "Create a uniquely suffixed branch if the branch already exists")
+	flag.StringVar(&config.checkScript, "check-script", "", "The script to check if the repository should be changed")
+	flag.StringVar(&checkScriptFile, "check-script-file", "", "Read the check script from a file")
+	flag.StringVar(&config.defaultBranch, "default-branch", "", "Only include repositories with the default branch")
+	flag.BoolVar(&config.deleteBranch, "delete-branch-on-merge", config.deleteBranch, "Delete the branch once its PR is merged")
+	flag.BoolVar(&config.enableDeleteBranch, "enable-delete-branch-on-merge", config.enableDeleteBranch, "Turn on automatic deletion of head branches after PRs are merged")
 	flag.StringVar(&config.desc, "desc", "", "The PR description")
+	flag.StringVar(&config.dockerImage, "docker-image", "", "Run the script inside a Docker container using the image")
 	flag.BoolVar(&showHelp, "help", showHelp, "Print this information and exit")
+	flag.Var(&language, "language", "Only include repositories with the primary language")
 	flag.BoolVar(&config.list, "list", config.list, "List PR associated with the branch")
+	flag.IntVar(&config.maxRepos, "max-repos", 0, "Limit the number of repositories to create or patch PRs in")
+	flag.BoolVar(&config.noMaintainerEdit, "no-maintainer-edit", config.noMaintainerEdit, "Don't allow maintainers to modify the PR")
 	flag.BoolVar(&config.noFork, "no-fork", config.noFork, "Don't include fork repositories")
 	flag.BoolVar(&config.noPrivate, "no-private", config.noPrivate, "Don't include private repositories")
 	flag.BoolVar(&config.noPublic, "no-public", config.noPublic, "Don't include public repositories")
 	flag.StringVar(&noRepo, "no-repo", "", "The pattern to reject repository names")
 	flag.BoolVar(&config.patch, "patch", config.patch, "Apply changes to the existing PR")
+	flag.DurationVar(&config.pause, "pause", 0, "Pause between creating or patching PRs")
+	flag.StringVar(&project, "project", "", "Add PRs to the project (v2) in the form owner/number")
 	flag.StringVar(&repo, "repo", "", "The pattern to match repository names")
+	flag.StringVar(&repoFile, "repo-file", "", "Read repository names from the file")
+	flag.BoolVar(&config.rollback, "rollback", config.rollback, "Roll back PRs recorded in the state file")
 	flag.Var(&review, "review", "The GitHub user login to request the PR review from")
+	flag.BoolVar(&config.reviewCodeowners, "review-codeowners", config.reviewCodeowners, "Request reviews from code owners of the changed paths")
 	flag.StringVar(&config.script, "script", "", "The script to apply PR changes")
 	flag.StringVar(&scriptFile, "script-file", "", "Read the script from a file")
 	flag.StringVar(&config.shell, "shell", config.shell, "The shell to use to run the script")
+	flag.Var(&sparsePath, "sparse-path", "Make a partial clone and check out only the path")
+	flag.StringVar(&config.stateFile, "state-file", "", "Record created PRs in the file to be able to roll them back")
+	flag.BoolVar(&config.ssh, "ssh", config.ssh, "Clone and push over SSH using the SSH agent")
+	flag.StringVar(&config.sshKey, "ssh-key", "", "Clone and push over SSH using the private key file")
 	flag.StringVar(&config.title, "title", "", "The PR title")
 	flag.BoolVar(&config.token, "token", config.token, "Prompt for Access Token")
+	flag.Var(&topic, "topic", "Only include repositories with the topic")
+	flag.Var(&trailer, "trailer", "The trailer to append to the commit message in the form key:value")
+	flag.StringVar(&config.urlFile, "url-file", "", "Append created PRs to the file")
+	flag.BoolVar(&config.usePRTemplate, "use-pr-template", config.usePRTemplate, "Use the repository PR template as the PR description")
+	flag.StringVar(&config.verify, "verify", "", "The command to verify changes before pushing")
 	flag.BoolVar(&showVersion, "version", showVersion, "Print version and exit")
 	flag.Usage = usage
 	flag.Parse()
@@ -156,6 +274,13 @@ func readConfig() (config, error) {
 		os.Exit(0)
 	}
 
+	if config.rollback {
+		if config.stateFile == "" {
+			return config, fmt.Errorf("state-file is required to roll back")
+		}
+		return config, nil // No other options apply.
+	}
+
 	parts := strings.Split(flag.Arg(0), "/")
 	nparts := len(parts)
 	if nparts > 0 {
@@ -168,7 +293,19 @@ func readConfig() (config, error) {
 		return config, fmt.Errorf("invalid owner or repository name %s", flag.Arg(0))
 	}
 
-	if config.owner == "" {
+	if repoFile != "" {
+		if flag.Arg(0) != "" {
+			return config, fmt.Errorf("repo-file and owner are mutually exclusive")
+		}
+		if config.repoNames, err = gh.ReadRepoListFile(repoFile); err != nil {
+			return config, fmt.Errorf("can't read repo file %s: %s", repoFile, err)
+		}
+		if len(config.repoNames) == 0 {
+			return config, fmt.Errorf("repo file %s is empty", repoFile)
+		}
+	}
+
+	if config.owner == "" && len(config.repoNames) == 0 {
 		return config, fmt.Errorf("owner is required")
 	}
 
@@ -176,6 +313,18 @@ func readConfig() (config, error) {
 		return config, fmt.Errorf("list and patch are mutually exclusive")
 	}
 
+	if config.deleteBranch && !config.list {
+		return config, fmt.Errorf("delete-branch-on-merge requires list")
+	}
+
+	if config.enableDeleteBranch && config.list {
+		return config, fmt.Errorf("list and enable-delete-branch-on-merge are mutually exclusive")
+	}
+
+	if config.comment != "" && !config.patch {
+		return config, fmt.Errorf("comment requires patch")
+	}
+
 	if config.noPrivate && config.noPublic {
 		return config, fmt.Errorf("no-private and no-public are mutually exclusive")
 	}
@@ -184,6 +333,18 @@ func readConfig() (config, error) {
 		return config, fmt.Errorf("branch is required")
 	}
 
+	if config.sshKey != "" {
+		config.ssh = true // Implies SSH.
+	}
+
+	if config.maxRepos < 0 {
+		return config, fmt.Errorf("max-repos should be positive")
+	}
+
+	if config.pause < 0 {
+		return config, fmt.Errorf("pause should be positive")
+	}
+
 	if config.script == "" && scriptFile != "" {
 		contents, err := ioutil.ReadFile(scriptFile)
 		if err != nil {
@@ -195,10 +356,36 @@ func readConfig() (config, error) {
 		return config, fmt.Errorf("script is required")
 	}
 
+	if config.checkScript == "" && checkScriptFile != "" {
+		contents, err := ioutil.ReadFile(checkScriptFile)
+		if err != nil {
+			return config, fmt.Errorf("can't read check script file %s: %s", checkScriptFile, err)
+		}
+		config.checkScript = string(contents)
+	}
+
+	config.branch = expandBranch(config.branch, config.script, time.Now())
+
 	if !config.list && config.shell == "" {
 		return config, fmt.Errorf("shell is required")
 	}
 
+	if config.commitMessage == "" && commitMessageFile != "" {
+		contents, err := ioutil.ReadFile(commitMessageFile)
+		if err != nil {
+			return config, fmt.Errorf("can't read commit message file %s: %s", commitMessageFile, err)
+		}
+		config.commitMessage = strings.TrimSpace(string(contents))
+	}
+
+	for _, t := range trailer {
+		formatted, err := formatTrailer(t)
+		if err != nil {
+			return config, err
+		}
+		config.trailers = append(config.trailers, formatted)
+	}
+
 	if !config.list && config.title == "" && config.commitMessage == "" {
 		return config, fmt.Errorf("either title or commit-message must be provided")
 	}
@@ -233,12 +420,55 @@ func readConfig() (config, error) {
 		}
 	}
 
+	for _, pattern := range addPath {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err = filepath.Match(pattern, ""); err != nil {
+			return config, fmt.Errorf("invalid add-path pattern: %s: %s", pattern, err)
+		}
+		config.addPaths = append(config.addPaths, pattern)
+	}
+
+	for _, path := range sparsePath {
+		path = strings.Trim(strings.TrimSpace(path), "/")
+		if path == "" {
+			continue
+		}
+		config.sparsePaths = append(config.sparsePaths, path)
+	}
+
+	for _, t := range topic {
+		if t = strings.TrimSpace(t); t != "" {
+			config.topics = append(config.topics, t)
+		}
+	}
+
+	for _, l := range language {
+		if l = strings.TrimSpace(l); l != "" {
+			config.languages = append(config.languages, l)
+		}
+	}
+
 	if repo != "" {
 		if config.repoRegexp, err = regexp.Compile(repo); err != nil {
 			return config, fmt.Errorf("invalid repo pattern: %s", err)
 		}
 	}
 
+	if project != "" {
+		if config.projectOwner, config.projectNumber, err = parseProject(project); err != nil {
+			return config, err
+		}
+	}
+
+	if closesIssue != "" {
+		if config.closesIssueRegexp, err = regexp.Compile(closesIssue); err != nil {
+			return config, fmt.Errorf("invalid closes-issue pattern: %s", err)
+		}
+	}
+
 	if noRepo != "" {
 		if config.noRepoRegexp, err = regexp.Compile(noRepo); err != nil {
 			return config, fmt.Errorf("invalid no-repo pattern: %s", err)
@@ -272,24 +502,48 @@ func run(ctx context.Context) error {
 
 	prmaker.ghToken = token
 
-	prmaker.gh = github.NewClient(oauth2.NewClient(ctx, oauth2.StaticTokenSource(
+	httpClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
-	)))
+	))
+	// Retry API calls that failed due to rate limiting or transient errors.
+	httpClient.Transport = gh.NewRetryTransport(httpClient.Transport)
+	prmaker.gh = github.NewClient(httpClient)
+
+	if prmaker.config.rollback {
+		return prmaker.rollback(ctx)
+	}
+
+	prmaker.gitAuth, err = prmaker.newGitAuth()
+	if err != nil {
+		return err
+	}
 
 	return prmaker.create(ctx)
 }
 
 func (p *prmaker) create(ctx context.Context) error {
-	repos, err := gh.NewRepoFinder(p.gh).Find(ctx, gh.RepoFilter{
-		Owner:        p.config.owner,
-		Repo:         p.config.repo,
-		RepoRegexp:   p.config.repoRegexp,
-		Archived:     false,
-		NoPrivate:    p.config.noPrivate,
-		NoPublic:     p.config.noPublic,
-		NoFork:       p.config.noFork,
-		NoRepoRegexp: p.config.noRepoRegexp,
-	})
+	var (
+		repos  []*github.Repository
+		finder = gh.NewRepoFinder(p.gh)
+		err    error
+	)
+	if len(p.config.repoNames) > 0 {
+		repos, err = p.getRepos(ctx, finder)
+	} else {
+		repos, err = finder.Find(ctx, gh.RepoFilter{
+			Owner:         p.config.owner,
+			Repo:          p.config.repo,
+			RepoRegexp:    p.config.repoRegexp,
+			Archived:      false,
+			NoPrivate:     p.config.noPrivate,
+			NoPublic:      p.config.noPublic,
+			NoFork:        p.config.noFork,
+			NoRepoRegexp:  p.config.noRepoRegexp,
+			DefaultBranch: p.config.defaultBranch,
+			Topics:        p.config.topics,
+			Languages:     p.config.languages,
+		})
+	}
 	if err != nil {
 		return err
 	}
@@ -320,21 +574,77 @@ func (p *prmaker) create(ctx context.Context) error {
 		}
 	}
 
+	if p.config.stateFile != "" && !p.config.list {
+		p.state, err = readState(p.config.stateFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	if p.config.urlFile != "" && !p.config.list {
+		p.urlFile, err = os.OpenFile(p.config.urlFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("can't open url file: %s", err)
+		}
+		defer p.urlFile.Close()
+	}
+
+	if p.config.projectOwner != "" && !p.config.list {
+		p.projectID, err = p.getProjectID(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if p.config.reviewCodeowners {
+		user, _, err := p.gh.Users.Get(ctx, "")
+		if err != nil {
+			return fmt.Errorf("can't read the authenticated user: %s", err)
+		}
+		p.login = user.GetLogin()
+	}
+
+	if !p.config.list {
+		cleanup, err := p.writeScripts()
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+
 	var (
-		repo  *github.Repository
-		prNo  int
-		pr    *github.PullRequest
-		prURL string
+		repo    *github.Repository
+		prNo    int
+		pr      *github.PullRequest
+		prURL   string
+		changed int // The number of repositories where PRs were created or patched.
 	)
 	for _, repo = range repos {
+		if p.config.maxRepos > 0 && changed >= p.config.maxRepos {
+			fmt.Fprintf(p.stderr, "Reached the limit of %d repositories\n", p.config.maxRepos)
+			break
+		}
+
 		fmt.Fprint(p.stderr, repo.GetFullName())
 
 		// Check if the remote branch already exists.
-		_, resp, err := p.gh.Repositories.GetBranch(ctx, p.config.owner, repo.GetName(), p.config.branch)
+		branch := p.config.branch
+		_, resp, err := p.gh.Repositories.GetBranch(ctx, repo.GetOwner().GetLogin(), repo.GetName(), branch)
 		switch err {
 		case nil:
+			if p.config.branchSuffix && !p.config.patch && !p.config.list {
+				// Creating a new PR in a uniquely suffixed branch.
+				branch, err = p.uniqueBranch(ctx, repo, branch)
+				if err != nil {
+					fmt.Fprintln(p.stdout)
+					return err
+				}
+				fmt.Fprint(p.stdout, " ", branch)
+				break
+			}
+
 			prURL = ""
-			pr, err = p.getPullForBranch(ctx, repo, p.config.branch)
+			pr, err = p.getPullForBranch(ctx, repo, branch)
 			if err == nil {
 				prURL = pr.GetHTMLURL()
 			}
@@ -346,6 +656,18 @@ func (p *prmaker) create(ctx context.Context) error {
 						fmt.Fprintln(p.stdout)
 						continue
 					}
+				} else if p.config.deleteBranch {
+					deleted, err := p.deleteMergedBranch(ctx, repo, branch)
+					if err != nil {
+						fmt.Fprintln(p.stdout)
+						return err
+					}
+					if deleted {
+						fmt.Fprintln(p.stdout, " PR merged, branch deleted")
+					} else {
+						fmt.Fprintln(p.stdout, " PR not found")
+					}
+					continue
 				} else {
 					fmt.Fprintln(p.stdout, " PR not found")
 					continue
@@ -372,20 +694,16 @@ func (p *prmaker) create(ctx context.Context) error {
 			return fmt.Errorf("unexpected condition for list flag")
 		}
 
-		scriptFile, err := ioutil.TempFile("", "gh-pr-script")
-		if err != nil {
-			fmt.Fprintln(p.stdout)
-			return fmt.Errorf("can't create temp file: %s", err)
-		}
-		scriptFile.WriteString(p.config.script)
-		defer func() {
-			scriptFile.Close()
-			os.Remove(scriptFile.Name()) // Clean up.
-		}()
-
-		err = p.apply(ctx, repo, scriptFile.Name())
+		paths, err := p.apply(ctx, repo, branch)
+		noChanges := errors.Is(err, errNoChanges)
 		switch {
 		case err == nil:
+		case errors.Is(err, errSkipped):
+			fmt.Fprintln(p.stdout, " skipped")
+			continue
+		case errors.Is(err, errVerifyFailed):
+			fmt.Fprintln(p.stdout, " verification failed")
+			continue
 		case errors.Is(err, errNoChanges):
 			fmt.Fprint(p.stdout, " no changes")
 			if !p.config.patch {
@@ -400,13 +718,47 @@ func (p *prmaker) create(ctx context.Context) error {
 			return err
 		}
 
+		// Throttle after the PR has been created or patched in the previous repository.
+		// Repositories that are skipped or have no changes don't cost a pause.
+		if changed > 0 && p.config.pause > 0 && !noChanges {
+			if err = gh.Sleep(ctx, p.config.pause); err != nil {
+				fmt.Fprintln(p.stdout)
+				return err
+			}
+		}
+
+		body := p.config.desc
+		if p.config.usePRTemplate {
+			template, err := p.getPRTemplate(ctx, repo)
+			if err != nil {
+				fmt.Fprintf(p.stderr, "%s: error reading PR template: %s\n", repo.GetFullName(), err)
+			}
+			body = applyPRTemplate(template, body)
+		}
+		if p.config.closesIssueRegexp != nil {
+			numbers, err := p.findIssuesToClose(ctx, repo)
+			if err != nil {
+				fmt.Fprintf(p.stderr, "%s\n", err)
+			}
+			body = appendClosesIssues(body, numbers)
+		}
+
+		reviewers, teamReviewers := p.config.reviewers, []string(nil)
+		if p.config.reviewCodeowners && len(paths) > 0 {
+			reviewers, teamReviewers, err = p.codeownersReviewers(ctx, repo, paths)
+			if err != nil {
+				fmt.Fprintf(p.stderr, "%s: error reading CODEOWNERS: %s\n", repo.GetFullName(), err)
+			}
+		}
+
 		if !p.config.patch {
 			// Create a new PR when not in the patch mode.
-			pr, _, err = p.gh.PullRequests.Create(ctx, p.config.owner, repo.GetName(), &github.NewPullRequest{
-				Title: &p.config.title,
-				Head:  &p.config.branch,
-				Base:  repo.DefaultBranch,
-				Body:  &p.config.desc,
+			pr, _, err = p.gh.PullRequests.Create(ctx, repo.GetOwner().GetLogin(), repo.GetName(), &github.NewPullRequest{
+				Title:               &p.config.title,
+				Head:                &branch,
+				Base:                repo.DefaultBranch,
+				Body:                &body,
+				MaintainerCanModify: github.Bool(!p.config.noMaintainerEdit),
 			})
 			if err != nil {
 				fmt.Fprintln(p.stdout)
@@ -414,15 +766,29 @@ func (p *prmaker) create(ctx context.Context) error {
 			}
 
 			fmt.Fprint(p.stdout, " ", pr.GetHTMLURL())
+
+			if err = p.recordPR(repo, branch, pr, reviewers, teamReviewers); err != nil {
+				fmt.Fprintln(p.stdout)
+				return err
+			}
+
+			if p.urlFile != nil {
+				// Written as soon as the PR is created to survive an interrupted run.
+				_, err = fmt.Fprintf(p.urlFile, "%s %s\n", repo.GetFullName(), pr.GetHTMLURL())
+				if err != nil {
+					fmt.Fprintln(p.stdout)
+					return fmt.Errorf("can't write to url file: %s", err)
+				}
+			}
 		}
 
 		prNo = pr.GetNumber()
 
 		// Add or update reviewers.
-		addReviewers := p.config.reviewers
+		addReviewers := reviewers
 		var deleteReviewers []string
 		if p.config.patch && len(addReviewers) > 0 {
-			reviewers, _, err := p.gh.PullRequests.ListReviewers(ctx, p.config.owner, repo.GetName(), prNo, nil)
+			reviewers, _, err := p.gh.PullRequests.ListReviewers(ctx, repo.GetOwner().GetLogin(), repo.GetName(), prNo, nil)
 			if err != nil {
 				fmt.Fprintln(p.stdout)
 				fmt.Fprintf(p.stderr, "%s: error requesting PR reviewers: %s\n", repo.GetFullName(), err)
@@ -436,9 +802,10 @@ func (p *prmaker) create(ctx context.Context) error {
 				}
 			}
 		}
-		if len(addReviewers) > 0 {
-			_, _, err = p.gh.PullRequests.RequestReviewers(ctx, p.config.owner, repo.GetName(), prNo, github.ReviewersRequest{
-				Reviewers: addReviewers,
+		if len(addReviewers) > 0 || len(teamReviewers) > 0 {
+			_, _, err = p.gh.PullRequests.RequestReviewers(ctx, repo.GetOwner().GetLogin(), repo.GetName(), prNo, github.ReviewersRequest{
+				Reviewers:     addReviewers,
+				TeamReviewers: teamReviewers,
 			})
 			if err != nil {
 				fmt.Fprintln(p.stdout)
@@ -446,7 +813,7 @@ func (p *prmaker) create(ctx context.Context) error {
 			}
 		}
 		if len(deleteReviewers) > 0 {
-			_, err = p.gh.PullRequests.RemoveReviewers(ctx, p.config.owner, repo.GetName(), prNo, github.ReviewersRequest{
+			_, err = p.gh.PullRequests.RemoveReviewers(ctx, repo.GetOwner().GetLogin(), repo.GetName(), prNo, github.ReviewersRequest{
 				Reviewers: deleteReviewers,
 			})
 			if err != nil {
@@ -459,7 +826,7 @@ func (p *prmaker) create(ctx context.Context) error {
 		addAssignees := p.config.assignees
 		var deleteAssignees []string
 		if p.config.patch && len(addAssignees) > 0 {
-			issue, _, err := p.gh.Issues.Get(ctx, p.config.owner, repo.GetName(), prNo)
+			issue, _, err := p.gh.Issues.Get(ctx, repo.GetOwner().GetLogin(), repo.GetName(), prNo)
 			if err != nil {
 				fmt.Fprintln(p.stdout)
 				fmt.Fprintf(p.stderr, "%s: error retrieving PR: %s\n", repo.GetFullName(), err)
@@ -475,22 +842,34 @@ func (p *prmaker) create(ctx context.Context) error {
 			}
 		}
 		if len(addAssignees) > 0 {
-			_, _, err = p.gh.Issues.AddAssignees(ctx, p.config.owner, repo.GetName(), prNo, addAssignees)
+			_, _, err = p.gh.Issues.AddAssignees(ctx, repo.GetOwner().GetLogin(), repo.GetName(), prNo, addAssignees)
 			if err != nil {
 				fmt.Fprintln(p.stdout)
 				fmt.Fprintf(p.stderr, "%s: error assigning the PR: %s\n", repo.GetFullName(), err)
 			}
 		}
 		if len(deleteAssignees) > 0 {
-			_, _, err = p.gh.Issues.RemoveAssignees(ctx, p.config.owner, repo.GetName(), prNo, deleteAssignees)
+			_, _, err = p.gh.Issues.RemoveAssignees(ctx, repo.GetOwner().GetLogin(), repo.GetName(), prNo, deleteAssignees)
 			if err != nil {
 				fmt.Fprintln(p.stdout)
 				fmt.Fprintf(p.stderr, "%s: error removing assignees: %s\n", repo.GetFullName(), err)
 			}
 		}
 
+		// Comment on the PR instead of updating its title and body.
+		// There is nothing to comment on if the patch didn't change anything.
+		if p.config.patch && p.config.comment != "" && !noChanges {
+			_, _, err = p.gh.Issues.CreateComment(ctx, repo.GetOwner().GetLogin(), repo.GetName(), prNo, &github.IssueComment{
+				Body: &p.config.comment,
+			})
+			if err != nil {
+				fmt.Fprintln(p.stdout)
+				fmt.Fprintf(p.stderr, "%s: error commenting on PR: %s\n", repo.GetFullName(), err)
+			}
+		}
+
 		// Update title and/or body of the PR.
-		if p.config.patch {
+		if p.config.patch && p.config.comment == "" {
 			var (
 				updatePR bool
 				updates  github.PullRequest
@@ -499,26 +878,142 @@ func (p *prmaker) create(ctx context.Context) error {
 				updates.Title = &p.config.title
 				updatePR = true
 			}
-			if p.config.desc != "" && updates.Body != &p.config.desc {
-				updates.Body = &p.config.desc
+			// Only replace the description when a new one has been supplied
+			// so that edits made to the PR by hand are preserved.
+			if p.config.desc != "" {
+				updates.Body = &body
 				updatePR = true
 			}
 
 			if updatePR {
-				pr, _, err = p.gh.PullRequests.Edit(ctx, p.config.owner, repo.GetName(), prNo, &updates)
+				updated, _, err := p.gh.PullRequests.Edit(ctx, repo.GetOwner().GetLogin(), repo.GetName(), prNo, &updates)
 				if err != nil {
 					fmt.Fprintln(p.stdout)
 					fmt.Fprintf(p.stderr, "%s: error updating PR: %s\n", repo.GetFullName(), err)
+				} else {
+					pr = updated
 				}
 			}
 		}
 
+		// Make sure head branches are deleted after PRs are merged.
+		if p.config.enableDeleteBranch {
+			if err = p.enableDeleteBranchOnMerge(ctx, repo); err != nil {
+				fmt.Fprintln(p.stdout)
+				fmt.Fprintf(p.stderr, "%s: error turning on delete branch on merge: %s\n", repo.GetFullName(), err)
+			}
+		}
+
+		// Add the PR to the project.
+		if p.projectID != "" {
+			if err = p.addToProject(ctx, pr); err != nil {
+				fmt.Fprintln(p.stdout)
+				fmt.Fprintf(p.stderr, "%s: error adding PR to project: %s\n", repo.GetFullName(), err)
+			}
+		}
+
+		changed++
 		fmt.Fprintln(p.stdout)
 	}
 
 	return nil
 }
 
+// getRepos gets repositories listed in the repo file skipping archived ones
+// and ones that don't match the repository filters.
+func (p *prmaker) getRepos(ctx context.Context, finder *gh.RepoFinder) ([]*github.Repository, error) {
+	repos, err := finder.Get(ctx, p.config.repoNames)
+	if err != nil {
+		return nil, err
+	}
+
+	n := 0
+	for _, repo := range repos {
+		if repo.GetArchived() {
+			fmt.Fprintf(p.stderr, "%s: archived, skipping\n", repo.GetFullName())
+			continue
+		}
+		repos[n] = repo
+		n++
+	}
+
+	return gh.Filter(repos[:n], gh.RepoFilter{
+		RepoRegexp:    p.config.repoRegexp,
+		NoPrivate:     p.config.noPrivate,
+		NoPublic:      p.config.noPublic,
+		NoFork:        p.config.noFork,
+		NoRepoRegexp:  p.config.noRepoRegexp,
+		DefaultBranch: p.config.defaultBranch,
+		Topics:        p.config.topics,
+		Languages:     p.config.languages,
+	}), nil
+}
+
+// codeownersReviewers returns reviewers requested with the review flag
+// along with code owners of the changed paths. The authenticated user
+// is excluded since a review can't be requested from the PR author.
+func (p *prmaker) codeownersReviewers(ctx context.Context, repo *github.Repository, paths []string) ([]string, []string, error) {
+	codeowners, err := p.getCodeowners(ctx, repo)
+	if err != nil {
+		return p.config.reviewers, nil, err
+	}
+
+	users, teams := codeowners.reviewers(paths)
+	reviewers := append([]string{}, p.config.reviewers...)
+	for _, user := range users {
+		if strings.EqualFold(user, p.login) || contains(reviewers, user) {
+			continue
+		}
+		reviewers = append(reviewers, user)
+	}
+
+	return reviewers, teams, nil
+}
+
+// maxBranchSuffix limits the number of attempts to find a unique branch name.
+const maxBranchSuffix = 100
+
+// uniqueBranch finds a branch name that doesn't exist in the repository
+// by adding a numeric suffix to the branch name.
+func (p *prmaker) uniqueBranch(ctx context.Context, repo *github.Repository, branch string) (string, error) {
+	for i := 2; i <= maxBranchSuffix; i++ {
+		name := fmt.Sprintf("%s-%d", branch, i)
+		_, resp, err := p.gh.Repositories.GetBranch(ctx, repo.GetOwner().GetLogin(), repo.GetName(), name)
+		if err == nil {
+			continue
+		}
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return name, nil
+		}
+		return "", fmt.Errorf("%s: error checking branch: %s", repo.GetFullName(), err)
+	}
+
+	return "", fmt.Errorf("%s: can't find a unique name for branch %s", repo.GetFullName(), branch)
+}
+
+// maxPushRetries is the maximum number of times a failed git push is retried.
+const maxPushRetries = 3
+
+// retryPush calls push retrying failed attempts with exponential backoff.
+func (p *prmaker) retryPush(ctx context.Context, repo *github.Repository, push func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := push()
+		if err == nil || attempt >= maxPushRetries || errors.Is(err, context.Canceled) ||
+			errors.Is(err, git.NoErrAlreadyUpToDate) ||
+			errors.Is(err, transport.ErrAuthenticationRequired) ||
+			errors.Is(err, transport.ErrAuthorizationFailed) ||
+			errors.Is(err, transport.ErrRepositoryNotFound) {
+			return err
+		}
+
+		wait := gh.Backoff(attempt, 2*time.Second, 30*time.Second)
+		fmt.Fprintf(p.stderr, "%s: git push error: %s, retrying in %s\n", repo.GetFullName(), err, wait.Round(time.Second))
+		if err := gh.Sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
 func (p *prmaker) getPullForBranch(ctx context.Context, repo *github.Repository, branch string) (*github.PullRequest, error) {
 	var (
 		pulls []*github.PullRequest
@@ -527,7 +1022,7 @@ func (p *prmaker) getPullForBranch(ctx context.Context, repo *github.Repository,
 		opts  = &github.PullRequestListOptions{ListOptions: github.ListOptions{PerPage: 100}}
 	)
 	for {
-		pulls, resp, err = p.gh.PullRequests.List(ctx, p.config.owner, repo.GetName(), opts)
+		pulls, resp, err = p.gh.PullRequests.List(ctx, repo.GetOwner().GetLogin(), repo.GetName(), opts)
 		if err != nil {
 			return nil, fmt.Errorf("%s: can't read pull requests: %s", repo.GetName(), err)
 		}
@@ -547,46 +1042,124 @@ func (p *prmaker) getPullForBranch(ctx context.Context, repo *github.Repository,
 	return nil, nil
 }
 
-var errNoChanges = fmt.Errorf("no changes were made")
+// deleteMergedBranch deletes the branch if its PR has been merged.
+// It reports whether the branch has been deleted.
+func (p *prmaker) deleteMergedBranch(ctx context.Context, repo *github.Repository, branch string) (bool, error) {
+	owner := repo.GetOwner().GetLogin()
+	pulls, _, err := p.gh.PullRequests.List(ctx, owner, repo.GetName(), &github.PullRequestListOptions{
+		State: "closed",
+		Head:  owner + ":" + branch,
+	})
+	if err != nil {
+		return false, fmt.Errorf("%s: can't read pull requests: %s", repo.GetFullName(), err)
+	}
 
-func (p *prmaker) apply(ctx context.Context, repo *github.Repository, scriptPath string) error {
-	dir, err := ioutil.TempDir("", "gh-pr")
+	for _, pull := range pulls {
+		if pull.MergedAt == nil {
+			continue
+		}
+		_, err = p.gh.Git.DeleteRef(ctx, owner, repo.GetName(), "heads/"+branch)
+		if err != nil {
+			return false, fmt.Errorf("%s: error deleting branch: %s", repo.GetFullName(), err)
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// enableDeleteBranchOnMerge turns on the automatic deletion of head branches
+// after PRs are merged unless it's already on. Repository listings don't include
+// the setting so the repository is read first.
+func (p *prmaker) enableDeleteBranchOnMerge(ctx context.Context, repo *github.Repository) error {
+	current, _, err := p.gh.Repositories.Get(ctx, repo.GetOwner().GetLogin(), repo.GetName())
 	if err != nil {
 		return err
 	}
-	defer os.RemoveAll(dir) // Clean up.
+	if current.GetDeleteBranchOnMerge() {
+		return nil
+	}
 
-	auth := &gitHTTP.BasicAuth{
-		Username: "user", // Should be a non-empty string.
-		Password: p.ghToken,
+	_, _, err = p.gh.Repositories.Edit(ctx, repo.GetOwner().GetLogin(), repo.GetName(), &github.Repository{
+		DeleteBranchOnMerge: github.Bool(true),
+	})
+
+	return err
+}
+
+// newGitAuth creates the auth method to clone and push over HTTPS with
+// the access token or over SSH with the SSH agent or the private key file.
+func (p *prmaker) newGitAuth() (transport.AuthMethod, error) {
+	if !p.config.ssh {
+		return &gitHTTP.BasicAuth{
+			Username: "user", // Should be a non-empty string.
+			Password: p.ghToken,
+		}, nil
 	}
 
+	if p.config.sshKey != "" {
+		auth, err := gitSSH.NewPublicKeysFromFile("git", p.config.sshKey, "")
+		if err != nil {
+			return nil, fmt.Errorf("can't read ssh key %s: %s", p.config.sshKey, err)
+		}
+		return auth, nil
+	}
+
+	auth, err := gitSSH.NewSSHAgentAuth("git")
+	if err != nil {
+		return nil, fmt.Errorf("can't connect to ssh agent: %s", err)
+	}
+	return auth, nil
+}
+
+var (
+	errNoChanges    = fmt.Errorf("no changes were made")
+	errSkipped      = fmt.Errorf("skipped by the check script")
+	errVerifyFailed = fmt.Errorf("verification failed")
+)
+
+func (p *prmaker) apply(ctx context.Context, repo *github.Repository, branch string) ([]string, error) {
+	if len(p.config.sparsePaths) > 0 {
+		return p.applySparse(ctx, repo, branch)
+	}
+
+	dir, err := ioutil.TempDir("", "gh-pr")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir) // Clean up.
+
+	auth := p.gitAuth
+
 	// git clone [--depth=1].
 	cloneOptions := &git.CloneOptions{
 		URL:  repo.GetCloneURL(),
 		Auth: auth,
 	}
+	if p.config.ssh {
+		cloneOptions.URL = repo.GetSSHURL()
+	}
 	if !p.config.patch {
 		cloneOptions.Depth = 1
 	}
 	gitRepo, err := git.PlainCloneContext(ctx, dir, false, cloneOptions)
 	if err != nil {
-		return fmt.Errorf("%s: git clone error: %w", repo.GetFullName(), err)
+		return nil, fmt.Errorf("%s: git clone error: %w", repo.GetFullName(), err)
 	}
 
 	wrkTree, err := gitRepo.Worktree()
 	if err != nil {
-		return fmt.Errorf("%s: git worktree error: %w", repo.GetFullName(), err)
+		return nil, fmt.Errorf("%s: git worktree error: %w", repo.GetFullName(), err)
 	}
 
 	// git checkout [-b] branch.
 	checkoutOptions := &git.CheckoutOptions{
-		Branch: plumbing.ReferenceName("refs/heads/" + p.config.branch),
+		Branch: plumbing.ReferenceName("refs/heads/" + branch),
 	}
 	if !p.config.patch {
 		headRef, err := gitRepo.Head()
 		if err != nil {
-			return fmt.Errorf("%s: git show-ref error: %w", repo.GetFullName(), err)
+			return nil, fmt.Errorf("%s: git show-ref error: %w", repo.GetFullName(), err)
 		}
 		checkoutOptions.Hash = headRef.Hash()
 		checkoutOptions.Create = true
@@ -596,19 +1169,131 @@ func (p *prmaker) apply(ctx context.Context, repo *github.Repository, scriptPath
 			Auth:     auth,
 		})
 		if err != nil {
-			return fmt.Errorf("%s: git fetch error: %w", repo.GetFullName(), err)
+			return nil, fmt.Errorf("%s: git fetch error: %w", repo.GetFullName(), err)
 		}
 		checkoutOptions.Force = true
 	}
 
 	err = wrkTree.Checkout(checkoutOptions)
 	if err != nil {
-		return fmt.Errorf("%s: git checkout error: %w", repo.GetFullName(), err)
+		return nil, fmt.Errorf("%s: git checkout error: %w", repo.GetFullName(), err)
+	}
+
+	err = p.runScripts(repo, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.config.addPaths) > 0 {
+		// git add <path>...
+		for _, pattern := range p.config.addPaths {
+			err = wrkTree.AddGlob(pattern)
+			if err != nil && !errors.Is(err, git.ErrGlobNoMatches) {
+				return nil, fmt.Errorf("%s: git add error: %w", repo.GetFullName(), err)
+			}
+		}
+	} else {
+		// git add .
+		_, err = wrkTree.Add(".")
+		if err != nil {
+			return nil, fmt.Errorf("%s: git add error: %w", repo.GetFullName(), err)
+		}
+	}
+
+	// Make sure we have changes to commit.
+	gitStatus, err := wrkTree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("%s: git status error: %w", repo.GetFullName(), err)
+	}
+	paths := stagedPaths(gitStatus)
+	if len(paths) == 0 && !p.config.allowEmpty {
+		return nil, errNoChanges
+	}
+
+	// git commit.
+	_, err = wrkTree.Commit(p.commitMessage(), &git.CommitOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: git commit error: %w", repo.GetFullName(), err)
+	}
+
+	err = p.runVerify(repo, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// git push.
+	err = p.retryPush(ctx, repo, func() error {
+		return gitRepo.PushContext(ctx, &git.PushOptions{
+			RemoteName: "origin",
+			Auth:       auth,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: git push error: %w", repo.GetFullName(), err)
+	}
+
+	return paths, nil
+}
+
+// writeScripts writes the script, the check and the verification scripts
+// to temp files and returns the function to clean them up.
+func (p *prmaker) writeScripts() (func(), error) {
+	var paths []string
+	cleanup := func() {
+		for _, path := range paths {
+			os.Remove(path)
+		}
+	}
+
+	for _, script := range []struct {
+		body string
+		path *string
+	}{
+		{p.config.script, &p.scriptPath},
+		{p.config.checkScript, &p.checkScriptPath},
+		{p.config.verify, &p.verifyScriptPath},
+	} {
+		if script.body == "" {
+			continue
+		}
+
+		file, err := ioutil.TempFile("", "gh-pr-script")
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("can't create temp file: %s", err)
+		}
+		paths = append(paths, file.Name())
+
+		_, err = file.WriteString(script.body)
+		file.Close()
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("can't write temp file: %s", err)
+		}
+		*script.path = file.Name()
+	}
+
+	return cleanup, nil
+}
+
+// runScripts runs the check script, if any, and then the script
+// in the cloned repository located in dir.
+func (p *prmaker) runScripts(repo *github.Repository, dir string) error {
+	// Run the check script if any. Non-zero exit code means skip the repository.
+	if p.checkScriptPath != "" {
+		cmd := p.scriptCommand(dir, p.checkScriptPath)
+		cmdOut, err := cmd.Output()
+		if err != nil {
+			if _, ok := err.(*exec.ExitError); ok {
+				return errSkipped
+			}
+			p.stderr.Write(cmdOut)
+			return fmt.Errorf("%s: failed to run the check script: %w", repo.GetFullName(), err)
+		}
 	}
 
 	// Run the script with the choosen shell.
-	cmd := exec.Command(p.config.shell, scriptPath)
-	cmd.Dir = dir
+	cmd := p.scriptCommand(dir, p.scriptPath)
 	cmdOut, err := cmd.Output()
 	if err != nil {
 		p.stderr.Write(cmdOut)
@@ -618,22 +1303,32 @@ func (p *prmaker) apply(ctx context.Context, repo *github.Repository, scriptPath
 		return fmt.Errorf("%s: failed to apply changes: %w", repo.GetFullName(), err)
 	}
 
-	// git add .
-	_, err = wrkTree.Add(".")
-	if err != nil {
-		return fmt.Errorf("%s: git add error: %w", repo.GetFullName(), err)
+	return nil
+}
+
+// runVerify runs the verification command, if any,
+// in the cloned repository located in dir.
+func (p *prmaker) runVerify(repo *github.Repository, dir string) error {
+	if p.verifyScriptPath == "" {
+		return nil
 	}
 
-	// Make sure we have changes to commit.
-	gitStatus, err := wrkTree.Status()
+	cmd := p.scriptCommand(dir, p.verifyScriptPath)
+	cmdOut, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("%s: git status error: %w", repo.GetFullName(), err)
-	}
-	if gitStatus.IsClean() {
-		return errNoChanges
+		p.stderr.Write(cmdOut)
+		if _, ok := err.(*exec.ExitError); ok {
+			return errVerifyFailed
+		}
+		return fmt.Errorf("%s: failed to run the verification command: %w", repo.GetFullName(), err)
 	}
 
-	// git commit.
+	return nil
+}
+
+// commitMessage returns the commit message falling back
+// to the PR title and description if not set explicitly.
+func (p *prmaker) commitMessage() string {
 	commitMessage := p.config.commitMessage
 	if commitMessage == "" {
 		commitMessage = p.config.title
@@ -641,19 +1336,51 @@ func (p *prmaker) apply(ctx context.Context, repo *github.Repository, scriptPath
 			commitMessage += "\n\n" + p.config.desc
 		}
 	}
-	_, err = wrkTree.Commit(commitMessage, &git.CommitOptions{})
-	if err != nil {
-		return fmt.Errorf("%s: git commit error: %w", repo.GetFullName(), err)
+
+	return appendTrailers(commitMessage, p.config.trailers)
+}
+
+const (
+	dockerWorkDir    = "/src"              // The mount point of the clone inside the container.
+	dockerScriptPath = "/tmp/gh-pr-script" // The mount point of the script inside the container.
+)
+
+// scriptCommand builds the command to run the script in the cloned repository
+// located in dir either directly or inside a Docker container.
+func (p *prmaker) scriptCommand(dir, scriptPath string) *exec.Cmd {
+	var cmd *exec.Cmd
+	if p.config.dockerImage != "" {
+		cmd = exec.Command("docker", dockerArgs(p.config.dockerImage, p.config.shell, dir, scriptPath)...)
+	} else {
+		cmd = exec.Command(p.config.shell, scriptPath)
 	}
+	cmd.Dir = dir
 
-	// git push.
-	err = gitRepo.PushContext(ctx, &git.PushOptions{
-		RemoteName: "origin",
-		Auth:       auth,
-	})
-	if err != nil {
-		return fmt.Errorf("%s: git push error: %w", repo.GetFullName(), err)
+	return cmd
+}
+
+// dockerArgs returns docker run arguments to execute the script
+// with the shell inside a container with the clone bind-mounted.
+func dockerArgs(image, shell, dir, scriptPath string) []string {
+	return []string{
+		"run", "--rm",
+		"-v", dir + ":" + dockerWorkDir,
+		"-v", scriptPath + ":" + dockerScriptPath + ":ro",
+		"-w", dockerWorkDir,
+		image,
+		shell, dockerScriptPath,
 	}
+}
 
-	return nil
+// stagedPaths returns sorted paths of changes staged for commit.
+func stagedPaths(status git.Status) []string {
+	var paths []string
+	for path, fileStatus := range status {
+		if fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	return paths
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	gh "github.com/pmatseykanets/gh-tools/github"
+)
+
+// parseProject parses the project reference in the form owner/number.
+func parseProject(s string) (string, int, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 || parts[0] == "" {
+		return "", 0, fmt.Errorf("invalid project %s: should be in the form owner/number", s)
+	}
+
+	number, err := strconv.Atoi(parts[1])
+	if err != nil || number <= 0 {
+		return "", 0, fmt.Errorf("invalid project %s: should be in the form owner/number", s)
+	}
+
+	return parts[0], number, nil
+}
+
+// getProjectID resolves the node ID of the project (v2).
+func (p *prmaker) getProjectID(ctx context.Context) (string, error) {
+	query := `query($login: String!, $number: Int!) {
+  repositoryOwner(login: $login) {
+    ... on ProjectV2Owner {
+      projectV2(number: $number) {
+        id
+      }
+    }
+  }
+}`
+	var result struct {
+		RepositoryOwner *struct {
+			ProjectV2 *struct {
+				ID string `json:"id"`
+			} `json:"projectV2"`
+		} `json:"repositoryOwner"`
+	}
+	err := gh.GraphQL(ctx, p.gh, query, map[string]interface{}{
+		"login":  p.config.projectOwner,
+		"number": p.config.projectNumber,
+	}, &result)
+	if err != nil {
+		return "", fmt.Errorf("can't read project %s/%d: %s", p.config.projectOwner, p.config.projectNumber, err)
+	}
+	if result.RepositoryOwner == nil || result.RepositoryOwner.ProjectV2 == nil {
+		return "", fmt.Errorf("project %s/%d doesn't exist", p.config.projectOwner, p.config.projectNumber)
+	}
+
+	return result.RepositoryOwner.ProjectV2.ID, nil
+}
+
+// addToProject adds the PR to the project (v2).
+// Adding a PR that is already in the project is a no-op.
+func (p *prmaker) addToProject(ctx context.Context, pr *github.PullRequest) error {
+	query := `mutation($projectId: ID!, $contentId: ID!) {
+  addProjectV2ItemById(input: {projectId: $projectId, contentId: $contentId}) {
+    item {
+      id
+    }
+  }
+}`
+
+	return gh.GraphQL(ctx, p.gh, query, map[string]interface{}{
+		"projectId": p.projectID,
+		"contentId": pr.GetNodeID(),
+	}, nil)
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestParseProject(t *testing.T) {
+	tests := []struct {
+		in      string
+		owner   string
+		number  int
+		invalid bool
+	}{
+		{in: "org/5", owner: "org", number: 5},
+		{in: "org", invalid: true},
+		{in: "/5", invalid: true},
+		{in: "org/", invalid: true},
+		{in: "org/0", invalid: true},
+		{in: "org/five", invalid: true},
+		{in: "org/5/6", invalid: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			owner, number, err := parseProject(tt.in)
+			if tt.invalid {
+				if err == nil {
+					t.Errorf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if owner != tt.owner || number != tt.number {
+				t.Errorf("Expected %s/%d got %s/%d", tt.owner, tt.number, owner, number)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// applySparse applies changes using a partial clone with sparse checkout
+// limited to the sparse paths. go-git doesn't support partial clones
+// nor sparse checkouts so it shells out to the git command line tool.
+func (p *prmaker) applySparse(ctx context.Context, repo *github.Repository, branch string) ([]string, error) {
+	dir, err := ioutil.TempDir("", "gh-pr")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir) // Clean up.
+
+	url := repo.GetCloneURL()
+	if p.config.ssh {
+		url = repo.GetSSHURL()
+	}
+	ref := repo.GetDefaultBranch()
+	if p.config.patch {
+		ref = branch
+	}
+
+	// git clone --filter=blob:none --no-checkout --depth=1 --branch ref.
+	err = p.git(ctx, "", "clone", "--filter=blob:none", "--no-checkout", "--depth=1", "--branch", ref, url, dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: git clone error: %w", repo.GetFullName(), err)
+	}
+
+	// git sparse-checkout set path...
+	err = p.git(ctx, dir, "sparse-checkout", "init", "--cone")
+	if err != nil {
+		return nil, fmt.Errorf("%s: git sparse-checkout error: %w", repo.GetFullName(), err)
+	}
+	err = p.git(ctx, dir, append([]string{"sparse-checkout", "set"}, p.config.sparsePaths...)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: git sparse-checkout error: %w", repo.GetFullName(), err)
+	}
+
+	// git checkout ref.
+	err = p.git(ctx, dir, "checkout", ref)
+	if err != nil {
+		return nil, fmt.Errorf("%s: git checkout error: %w", repo.GetFullName(), err)
+	}
+	// git checkout -b branch.
+	if !p.config.patch {
+		err = p.git(ctx, dir, "checkout", "-b", branch)
+		if err != nil {
+			return nil, fmt.Errorf("%s: git checkout error: %w", repo.GetFullName(), err)
+		}
+	}
+
+	err = p.runScripts(repo, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// git add -A [path...].
+	args := []string{"add", "-A"}
+	if len(p.config.addPaths) > 0 {
+		args = append(append(args, "--"), p.config.addPaths...)
+	}
+	err = p.git(ctx, dir, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: git add error: %w", repo.GetFullName(), err)
+	}
+
+	// Make sure we have changes to commit.
+	out, err := p.gitOutput(ctx, dir, "diff", "--cached", "--name-only", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("%s: git diff error: %w", repo.GetFullName(), err)
+	}
+	paths := strings.FieldsFunc(out, func(r rune) bool { return r == 0 })
+	if len(paths) == 0 && !p.config.allowEmpty {
+		return nil, errNoChanges
+	}
+
+	// git commit [--allow-empty].
+	args = []string{"commit", "-m", p.commitMessage()}
+	if p.config.allowEmpty {
+		args = append(args, "--allow-empty")
+	}
+	err = p.git(ctx, dir, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: git commit error: %w", repo.GetFullName(), err)
+	}
+
+	err = p.runVerify(repo, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// git push.
+	err = p.retryPush(ctx, repo, func() error {
+		return p.git(ctx, dir, "push", "origin", branch)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: git push error: %w", repo.GetFullName(), err)
+	}
+
+	return paths, nil
+}
+
+// git runs the git command line tool in dir.
+// The output is written to stderr only if the command fails.
+func (p *prmaker) git(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), p.gitEnv()...)
+	cmdOut, err := cmd.CombinedOutput()
+	if err != nil {
+		p.stderr.Write(cmdOut)
+		return err
+	}
+
+	return nil
+}
+
+// gitOutput runs the git command line tool in dir and returns its output.
+func (p *prmaker) gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), p.gitEnv()...)
+	cmdOut, err := cmd.Output()
+	if err != nil {
+		if eerr, ok := err.(*exec.ExitError); ok {
+			p.stderr.Write(eerr.Stderr)
+		}
+		return "", err
+	}
+
+	return string(cmdOut), nil
+}
+
+// gitEnv returns environment variables to authenticate the git command line tool.
+// The access token is passed via the environment rather than arguments so that
+// it doesn't show up in the process list.
+func (p *prmaker) gitEnv() []string {
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+
+	if !p.config.ssh {
+		credentials := base64.StdEncoding.EncodeToString([]byte("user:" + p.ghToken))
+		return append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+	}
+
+	if p.config.sshKey != "" {
+		env = append(env, "GIT_SSH_COMMAND=ssh -i "+shellQuote(p.config.sshKey)+" -o IdentitiesOnly=yes")
+	}
+
+	return env
+}
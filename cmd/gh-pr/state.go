@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// runState records PRs created by a run so that they can be rolled back.
+type runState struct {
+	PullRequests []statePullRequest `json:"pull_requests"`
+}
+
+// statePullRequest represents a PR created by a run.
+type statePullRequest struct {
+	Repo          string   `json:"repo"` // The full repository name owner/repo.
+	Branch        string   `json:"branch"`
+	Number        int      `json:"number"`
+	URL           string   `json:"url"`
+	Reviewers     []string `json:"reviewers,omitempty"`
+	TeamReviewers []string `json:"team_reviewers,omitempty"` // Team slugs.
+}
+
+// readState reads the state file. A missing file yields an empty state.
+func readState(path string) (*runState, error) {
+	state := &runState{}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("can't read state file %s: %s", path, err)
+	}
+
+	if err = json.Unmarshal(contents, state); err != nil {
+		return nil, fmt.Errorf("can't parse state file %s: %s", path, err)
+	}
+
+	return state, nil
+}
+
+// writeState writes the state file. It writes to a temp file first
+// and then renames it so that an interrupted run doesn't corrupt the state.
+func writeState(path string, state *runState) error {
+	contents, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), ".gh-pr-state")
+	if err != nil {
+		return fmt.Errorf("can't write state file %s: %s", path, err)
+	}
+	defer os.Remove(file.Name()) // Clean up if renaming fails.
+
+	_, err = file.Write(contents)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("can't write state file %s: %s", path, err)
+	}
+
+	if err = os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("can't write state file %s: %s", path, err)
+	}
+
+	return nil
+}
+
+// recordPR adds the created PR to the state file if there is one.
+func (p *prmaker) recordPR(repo *github.Repository, branch string, pr *github.PullRequest, reviewers, teamReviewers []string) error {
+	if p.state == nil {
+		return nil
+	}
+
+	p.state.PullRequests = append(p.state.PullRequests, statePullRequest{
+		Repo:          repo.GetFullName(),
+		Branch:        branch,
+		Number:        pr.GetNumber(),
+		URL:           pr.GetHTMLURL(),
+		Reviewers:     reviewers,
+		TeamReviewers: teamReviewers,
+	})
+
+	return writeState(p.config.stateFile, p.state)
+}
+
+// rollback closes PRs recorded in the state file, removes requested reviews
+// and deletes pushed branches.
+func (p *prmaker) rollback(ctx context.Context) error {
+	state, err := readState(p.config.stateFile)
+	if err != nil {
+		return err
+	}
+
+	if len(state.PullRequests) == 0 {
+		fmt.Fprintln(p.stdout, "No PRs to roll back")
+		return nil
+	}
+
+	var failed []statePullRequest
+	for _, pr := range state.PullRequests {
+		fmt.Fprint(p.stdout, pr.Repo, " ", pr.URL)
+
+		if err := p.rollbackPR(ctx, pr); err != nil {
+			fmt.Fprintln(p.stdout)
+			fmt.Fprintf(p.stderr, "%s: %s\n", pr.Repo, err)
+			failed = append(failed, pr)
+			continue
+		}
+
+		fmt.Fprintln(p.stdout, " rolled back")
+	}
+
+	// Keep only PRs that failed to roll back so that the rollback can be retried.
+	state.PullRequests = failed
+	if err = writeState(p.config.stateFile, state); err != nil {
+		return err
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to roll back %d PRs", len(failed))
+	}
+
+	return nil
+}
+
+func (p *prmaker) rollbackPR(ctx context.Context, pr statePullRequest) error {
+	parts := strings.SplitN(pr.Repo, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid repository name %s", pr.Repo)
+	}
+	owner, name := parts[0], parts[1]
+
+	if len(pr.Reviewers) > 0 || len(pr.TeamReviewers) > 0 {
+		resp, err := p.gh.PullRequests.RemoveReviewers(ctx, owner, name, pr.Number, github.ReviewersRequest{
+			Reviewers:     pr.Reviewers,
+			TeamReviewers: pr.TeamReviewers,
+		})
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusUnprocessableEntity) {
+			return fmt.Errorf("error removing reviewers: %s", err)
+		}
+	}
+
+	_, _, err := p.gh.PullRequests.Edit(ctx, owner, name, pr.Number, &github.PullRequest{
+		State: github.String("closed"),
+	})
+	if err != nil {
+		return fmt.Errorf("error closing PR: %s", err)
+	}
+
+	resp, err := p.gh.Git.DeleteRef(ctx, owner, name, "heads/"+pr.Branch)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusUnprocessableEntity) {
+		// http.StatusUnprocessableEntity - the branch doesn't exist.
+		return fmt.Errorf("error deleting branch %s: %s", pr.Branch, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gh-pr-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state.json")
+
+	// Missing state file yields an empty state.
+	state, err := readState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 0, len(state.PullRequests); want != got {
+		t.Fatalf("Expected %d PRs got %d", want, got)
+	}
+
+	state.PullRequests = append(state.PullRequests, statePullRequest{
+		Repo:          "owner/repo",
+		Branch:        "upgrade",
+		Number:        1,
+		URL:           "https://github.com/owner/repo/pull/1",
+		Reviewers:     []string{"john"},
+		TeamReviewers: []string{"platform"},
+	})
+	if err = writeState(path, state); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := state; !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected\n%+v\ngot\n%+v", want, got)
+	}
+}
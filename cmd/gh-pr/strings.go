@@ -1,6 +1,12 @@
 package main
 
-import "strings"
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Returns true if the string needle is in the slice hay.
 // It uses case insensitive comparison.
@@ -12,3 +18,50 @@ func contains(hay []string, needle string) bool {
 	}
 	return false
 }
+
+// formatTrailer validates and formats a commit message trailer
+// given in the form key:value as "key: value".
+func formatTrailer(s string) (string, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid trailer %s: should be in the form key:value", s)
+	}
+
+	key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+	if key == "" || value == "" || strings.ContainsAny(key, " \t") {
+		return "", fmt.Errorf("invalid trailer %s: should be in the form key:value", s)
+	}
+
+	return key + ": " + value, nil
+}
+
+// appendTrailers appends trailers to the commit message
+// separating them from the body with a blank line.
+func appendTrailers(message string, trailers []string) string {
+	if len(trailers) == 0 {
+		return message
+	}
+
+	return strings.TrimRight(message, "\n") + "\n\n" + strings.Join(trailers, "\n")
+}
+
+// expandBranch replaces placeholders in the branch name:
+// {date} with the date in the form YYYYMMDD and
+// {hash} with the short hash of the script.
+func expandBranch(branch, script string, now time.Time) string {
+	if !strings.Contains(branch, "{") {
+		return branch
+	}
+
+	hash := sha1.Sum([]byte(script))
+
+	return strings.NewReplacer(
+		"{date}", now.Format("20060102"),
+		"{hash}", hex.EncodeToString(hash[:])[:7],
+	).Replace(branch)
+}
+
+// shellQuote quotes the string to be used as a single word in a shell command.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
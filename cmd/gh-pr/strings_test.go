@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestContains(t *testing.T) {
@@ -27,3 +28,98 @@ func TestContains(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatTrailer(t *testing.T) {
+	tests := []struct {
+		in      string
+		out     string
+		invalid bool
+	}{
+		{"Signed-off-by:Jane Doe <jane@example.com>", "Signed-off-by: Jane Doe <jane@example.com>", false},
+		{" Change-Id : I123 ", "Change-Id: I123", false},
+		{"Ref:https://example.com/1", "Ref: https://example.com/1", false},
+		{"Signed-off-by", "", true},
+		{"Signed-off-by:", "", true},
+		{":value", "", true},
+		{"Signed off by:Jane", "", true},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			got, err := formatTrailer(tt.in)
+			if tt.invalid {
+				if err == nil {
+					t.Errorf("Expected an error got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := tt.out; want != got {
+				t.Errorf("Expected %q got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestAppendTrailers(t *testing.T) {
+	tests := []struct {
+		message  string
+		trailers []string
+		out      string
+	}{
+		{"Fix", nil, "Fix"},
+		{"Fix", []string{"Change-Id: I123"}, "Fix\n\nChange-Id: I123"},
+		{"Fix\n\nBody\n", []string{"Change-Id: I123", "Signed-off-by: Jane"}, "Fix\n\nBody\n\nChange-Id: I123\nSigned-off-by: Jane"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if want, got := tt.out, appendTrailers(tt.message, tt.trailers); want != got {
+				t.Errorf("Expected %q got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestExpandBranch(t *testing.T) {
+	now := time.Date(2021, 3, 5, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		branch string
+		script string
+		out    string
+	}{
+		{"upgrade", "echo", "upgrade"},
+		{"upgrade-{date}", "echo", "upgrade-20210305"},
+		{"upgrade-{hash}", "echo", "upgrade-b2d21e7"},
+		{"upgrade-{date}-{hash}", "echo", "upgrade-20210305-b2d21e7"},
+		{"upgrade-{unknown}", "echo", "upgrade-{unknown}"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if want, got := tt.out, expandBranch(tt.branch, tt.script, now); want != got {
+				t.Errorf("Expected %q got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", "''"},
+		{"/home/user/.ssh/id_rsa", "'/home/user/.ssh/id_rsa'"},
+		{"/home/John Doe/.ssh/id_rsa", "'/home/John Doe/.ssh/id_rsa'"},
+		{"/tmp/it's", `'/tmp/it'\''s'`},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.want, shellQuote(tt.s); want != got {
+			t.Errorf("Expected %s got %s", want, got)
+		}
+	}
+}
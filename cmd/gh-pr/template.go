@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// prTemplateMarker is the marker in the PR template that is replaced
+// with the PR description. If there is no marker in the template
+// the description is prepended to the template.
+const prTemplateMarker = "<!-- gh-pr:desc -->"
+
+// prTemplatePaths are the locations GitHub looks up the PR template at.
+var prTemplatePaths = []string{
+	".github/PULL_REQUEST_TEMPLATE.md",
+	".github/pull_request_template.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	"docs/PULL_REQUEST_TEMPLATE.md",
+	"docs/pull_request_template.md",
+}
+
+// getPRTemplate returns the contents of the PR template in the default branch
+// of the repository or an empty string if there is none.
+func (p *prmaker) getPRTemplate(ctx context.Context, repo *github.Repository) (string, error) {
+	opts := &github.RepositoryContentGetOptions{Ref: repo.GetDefaultBranch()}
+	for _, path := range prTemplatePaths {
+		fileContents, _, resp, err := p.gh.Repositories.GetContents(ctx, repo.GetOwner().GetLogin(), repo.GetName(), path, opts)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return "", err
+		}
+		if fileContents == nil { // It's a directory.
+			continue
+		}
+
+		return fileContents.GetContent()
+	}
+
+	return "", nil
+}
+
+// applyPRTemplate injects the description into the PR template.
+func applyPRTemplate(template, desc string) string {
+	if strings.TrimSpace(template) == "" {
+		return desc
+	}
+
+	if strings.Contains(template, prTemplateMarker) {
+		return strings.Replace(template, prTemplateMarker, desc, 1)
+	}
+
+	if desc == "" {
+		return template
+	}
+
+	return desc + "\n\n" + template
+}
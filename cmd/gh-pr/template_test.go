@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestApplyPRTemplate(t *testing.T) {
+	tests := []struct {
+		desc     string
+		template string
+		body     string
+		want     string
+	}{
+		{"no template", "", "Fix", "Fix"},
+		{"blank template", "\n \n", "Fix", "Fix"},
+		{"marker", "## What\n" + prTemplateMarker + "\n## Why\n", "Fix", "## What\nFix\n## Why\n"},
+		{"no marker", "## Checklist\n", "Fix", "Fix\n\n## Checklist\n"},
+		{"no marker no description", "## Checklist\n", "", "## Checklist\n"},
+		{"marker no description", "## What\n" + prTemplateMarker + "\n", "", "## What\n\n"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+			if want, got := tt.want, applyPRTemplate(tt.template, tt.body); want != got {
+				t.Errorf("Expected %q got %q", want, got)
+			}
+		})
+	}
+}
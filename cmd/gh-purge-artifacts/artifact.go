@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// artifact is a GitHub Actions artifact along with the workflow run
+// that produced it, which go-github doesn't expose.
+type artifact struct {
+	github.Artifact
+	WorkflowRun *artifactWorkflowRun `json:"workflow_run,omitempty"`
+}
+
+type artifactWorkflowRun struct {
+	ID         int64  `json:"id"`
+	HeadBranch string `json:"head_branch"`
+	HeadSHA    string `json:"head_sha"`
+}
+
+// runID returns the ID of the workflow run that produced the artifact or 0 if it's unknown.
+func (a *artifact) runID() int64 {
+	if a.WorkflowRun == nil {
+		return 0
+	}
+
+	return a.WorkflowRun.ID
+}
+
+// expired reports whether the artifact has expired. Expired artifacts
+// don't occupy storage anymore but are listed until they're cleaned up.
+func (a *artifact) expired(now time.Time) bool {
+	if a.GetExpired() {
+		return true
+	}
+
+	return a.ExpiresAt != nil && !a.GetExpiresAt().After(now)
+}
+
+// listArtifacts lists all artifacts of the repository.
+func (p *purger) listArtifacts(ctx context.Context, owner, name string) ([]*artifact, error) {
+	var artifacts []*artifact
+	for page := 1; page != 0; {
+		u := fmt.Sprintf("repos/%s/%s/actions/artifacts?per_page=100&page=%d", owner, name, page)
+		req, err := p.gh.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		list := struct {
+			Artifacts []*artifact `json:"artifacts"`
+		}{}
+		resp, err := p.gh.Do(ctx, req, &list)
+		if err != nil {
+			return nil, err
+		}
+
+		artifacts = append(artifacts, list.Artifacts...)
+		page = resp.NextPage
+	}
+
+	return artifacts, nil
+}
+
+// workflowRun returns the workflow run. Runs are cached
+// since many artifacts are usually produced by the same run.
+func (p *purger) workflowRun(ctx context.Context, owner, name string, id int64) (*github.WorkflowRun, error) {
+	p.mu.Lock()
+	run, ok := p.runs[id]
+	p.mu.Unlock()
+	if ok {
+		return run, nil
+	}
+
+	run, resp, err := p.gh.Actions.GetWorkflowRunByID(ctx, owner, name, id)
+	if err != nil {
+		if resp == nil || resp.StatusCode != http.StatusNotFound {
+			return nil, err
+		}
+		run = nil // The run has been deleted.
+	}
+	p.mu.Lock()
+	p.runs[id] = run
+	p.mu.Unlock()
+
+	return run, nil
+}
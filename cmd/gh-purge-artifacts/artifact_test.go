@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+func TestArtifactExpired(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		artifact *artifact
+		want     bool
+	}{
+		{&artifact{}, false},
+		{&artifact{Artifact: github.Artifact{Expired: github.Bool(true)}}, true},
+		{&artifact{Artifact: github.Artifact{ExpiresAt: &github.Timestamp{Time: now.Add(time.Hour)}}}, false},
+		{&artifact{Artifact: github.Artifact{ExpiresAt: &github.Timestamp{Time: now.Add(-time.Hour)}}}, true},
+		{&artifact{Artifact: github.Artifact{ExpiresAt: &github.Timestamp{Time: now}}}, true},
+	}
+
+	for i, tt := range tests {
+		if want, got := tt.want, tt.artifact.expired(now); want != got {
+			t.Errorf("%d: Expected %v got %v", i, want, got)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// listReleaseAssets lists assets of all releases of the repository.
+func (p *purger) listReleaseAssets(ctx context.Context, owner, name string) ([]*github.ReleaseAsset, error) {
+	var assets []*github.ReleaseAsset
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		releases, resp, err := p.gh.Repositories.ListReleases(ctx, owner, name, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, release := range releases {
+			assets = append(assets, release.Assets...)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return assets, nil
+}
+
+// purgeRepoReleaseAssets purges assets attached to releases of the repository.
+// Name patterns match asset names.
+func (p *purger) purgeRepoReleaseAssets(ctx context.Context, repo *github.Repository) (int64, int64, error) {
+	owner := repo.GetOwner().GetLogin()
+	name := repo.GetName()
+
+	assets, err := p.listReleaseAssets(ctx, owner, name)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var matched []*github.ReleaseAsset
+	for _, asset := range assets {
+		if p.matchName(asset.GetName()) && p.match(asset.GetCreatedAt().Time, int64(asset.GetSize())) {
+			matched = append(matched, asset)
+		}
+	}
+
+	deleted, deletedSize, err := p.deleteItems(ctx, repo.GetFullName(), len(matched),
+		func(i int) int64 { return int64(matched[i].GetSize()) },
+		func(ctx context.Context, i int) error {
+			resp, err := p.gh.Repositories.DeleteReleaseAsset(ctx, owner, name, matched[i].GetID())
+			if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+				return nil // Already deleted e.g. by a retried request.
+			}
+			return err
+		},
+	)
+	p.report(repo.GetFullName(), deleted, int64(len(assets)), deletedSize)
+
+	return deleted, deletedSize, err
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// filterRuns returns artifacts produced by workflow runs on matching branches,
+// of closed pull requests and not of open ones.
+func (p *purger) filterRuns(ctx context.Context, owner, name string, artifacts []*artifact) ([]*artifact, error) {
+	var (
+		open *openPRs
+		err  error
+	)
+	if p.config.protectOpenPRs {
+		if open, err = p.listOpenPRs(ctx, owner, name); err != nil {
+			return nil, err
+		}
+	}
+
+	var filtered []*artifact
+	for _, a := range artifacts {
+		if p.config.branchRegexp != nil || open != nil {
+			branch, sha, err := p.runHead(ctx, owner, name, a)
+			if err != nil {
+				return nil, err
+			}
+			if p.config.branchRegexp != nil && !p.config.branchRegexp.MatchString(branch) {
+				continue
+			}
+			if open.match(branch, sha) {
+				continue
+			}
+		}
+
+		if p.config.closedPRsOnly {
+			closed, err := p.closedPRs(ctx, owner, name, a)
+			if err != nil {
+				return nil, err
+			}
+			if !closed {
+				continue
+			}
+		}
+
+		filtered = append(filtered, a)
+	}
+
+	return filtered, nil
+}
+
+// runHead returns the branch and the commit SHA of the workflow run that produced
+// the artifact. It returns empty strings if the run is unknown.
+func (p *purger) runHead(ctx context.Context, owner, name string, a *artifact) (string, string, error) {
+	if a.WorkflowRun != nil && a.WorkflowRun.HeadBranch != "" {
+		return a.WorkflowRun.HeadBranch, a.WorkflowRun.HeadSHA, nil
+	}
+	if a.runID() == 0 {
+		return "", "", nil
+	}
+
+	run, err := p.workflowRun(ctx, owner, name, a.runID())
+	if err != nil || run == nil {
+		return "", "", err
+	}
+
+	return run.GetHeadBranch(), run.GetHeadSHA(), nil
+}
+
+// openPRs holds heads of open pull requests of a repository.
+type openPRs struct {
+	branches map[string]bool // Head branches of pull requests from the repository itself.
+	shas     map[string]bool // Head commit SHAs including pull requests from forks.
+}
+
+// match reports whether the branch or the commit SHA of a workflow run
+// belongs to an open pull request. Branches of pull requests from forks
+// are ambiguous and therefore only their head commits are matched.
+func (o *openPRs) match(branch, sha string) bool {
+	if o == nil {
+		return false
+	}
+
+	return (branch != "" && o.branches[branch]) || (sha != "" && o.shas[sha])
+}
+
+// listOpenPRs lists heads of open pull requests of the repository.
+func (p *purger) listOpenPRs(ctx context.Context, owner, name string) (*openPRs, error) {
+	open := &openPRs{branches: map[string]bool{}, shas: map[string]bool{}}
+	opts := &github.PullRequestListOptions{State: "open", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		prs, resp, err := p.gh.PullRequests.List(ctx, owner, name, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pr := range prs {
+			open.shas[pr.GetHead().GetSHA()] = true
+			if strings.EqualFold(pr.GetHead().GetRepo().GetFullName(), owner+"/"+name) {
+				open.branches[pr.GetHead().GetRef()] = true
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return open, nil
+}
+
+// closedPRs reports whether the artifact was produced by a workflow run
+// of pull requests that are all closed or merged. Runs of pull requests
+// from forks don't reference them and therefore never match.
+func (p *purger) closedPRs(ctx context.Context, owner, name string, a *artifact) (bool, error) {
+	if a.runID() == 0 {
+		return false, nil
+	}
+	run, err := p.workflowRun(ctx, owner, name, a.runID())
+	if err != nil || run == nil || len(run.PullRequests) == 0 {
+		return false, err
+	}
+
+	for _, pr := range run.PullRequests {
+		state, err := p.pullRequestState(ctx, owner, name, pr.GetNumber())
+		if err != nil {
+			return false, err
+		}
+		if state != "closed" {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// pullRequestState returns the state of the pull request. States are cached
+// since a pull request usually has many workflow runs.
+func (p *purger) pullRequestState(ctx context.Context, owner, name string, number int) (string, error) {
+	key := fmt.Sprintf("%s/%s#%d", owner, name, number)
+	p.mu.Lock()
+	state, ok := p.prs[key]
+	p.mu.Unlock()
+	if ok {
+		return state, nil
+	}
+
+	pr, resp, err := p.gh.PullRequests.Get(ctx, owner, name, number)
+	if err != nil {
+		if resp == nil || resp.StatusCode != http.StatusNotFound {
+			return "", err
+		}
+		pr = nil // The pull request has been deleted.
+	}
+	state = "closed"
+	if pr != nil {
+		state = pr.GetState()
+	}
+
+	p.mu.Lock()
+	p.prs[key] = state
+	p.mu.Unlock()
+
+	return state, nil
+}
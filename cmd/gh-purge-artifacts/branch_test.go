@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestOpenPRsMatch(t *testing.T) {
+	open := &openPRs{
+		branches: map[string]bool{"feature": true},
+		shas:     map[string]bool{"abc123": true},
+	}
+
+	tests := []struct {
+		branch, sha string
+		want        bool
+	}{
+		{"feature", "def456", true},
+		{"patch-1", "abc123", true}, // A pull request from a fork.
+		{"main", "def456", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.want, open.match(tt.branch, tt.sha); want != got {
+			t.Errorf("%s@%s: Expected %v got %v", tt.branch, tt.sha, want, got)
+		}
+	}
+
+	var none *openPRs
+	if none.match("feature", "abc123") {
+		t.Errorf("Expected nil openPRs not to match")
+	}
+}
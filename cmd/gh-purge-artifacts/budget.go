@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/pmatseykanets/gh-tools/pool"
+)
+
+// storageUsage returns the storage used by artifacts.
+// Expired artifacts don't count towards the usage.
+func storageUsage(artifacts []*artifact, now time.Time) int64 {
+	var usage int64
+	for _, a := range artifacts {
+		if !a.expired(now) {
+			usage += a.GetSizeInBytes()
+		}
+	}
+
+	return usage
+}
+
+// overBudget returns the oldest candidates that have to be deleted to bring
+// the storage used by all artifacts under the budget.
+func overBudget(all, candidates []*artifact, budget int64, now time.Time) []*artifact {
+	usage := storageUsage(all, now)
+	if usage <= budget {
+		return nil
+	}
+
+	oldest := make([]*artifact, 0, len(candidates))
+	for _, a := range candidates {
+		if !a.expired(now) {
+			oldest = append(oldest, a)
+		}
+	}
+	sort.SliceStable(oldest, func(i, j int) bool {
+		return oldest[i].GetCreatedAt().Before(oldest[j].GetCreatedAt().Time)
+	})
+
+	var selected []*artifact
+	for _, a := range oldest {
+		if usage <= budget {
+			break
+		}
+		selected = append(selected, a)
+		usage -= a.GetSizeInBytes()
+	}
+
+	return selected
+}
+
+// purgeTotalBudget purges artifacts across repositories until all of them
+// combined are under the storage budget. It returns the number and the total
+// size of purged artifacts.
+func (p *purger) purgeTotalBudget(ctx context.Context, workers int, repos []*github.Repository) (int64, int64, error) {
+	var (
+		all        = make([][]*artifact, len(repos))
+		candidates = make([][]*artifact, len(repos))
+	)
+	err := pool.Run(ctx, p.config.repoConcurrency, len(repos), func(ctx context.Context, i int) error {
+		var err error
+		all[i], candidates[i], err = p.artifactCandidates(ctx, repos[i])
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var allArtifacts, allCandidates []*artifact
+	for i := range repos {
+		allArtifacts = append(allArtifacts, all[i]...)
+		allCandidates = append(allCandidates, candidates[i]...)
+	}
+	selected := map[int64]bool{}
+	for _, a := range overBudget(allArtifacts, allCandidates, p.config.targetSize, p.now) {
+		selected[a.GetID()] = true
+	}
+
+	var (
+		totalDeleted, totalSize int64
+		mu                      sync.Mutex
+	)
+	err = pool.Run(ctx, workers, len(repos), func(ctx context.Context, i int) error {
+		artifacts := p.filter(candidates[i], func(a *artifact) bool { return selected[a.GetID()] })
+		p.progress.startRepo(repos[i].GetFullName())
+		deleted, size, err := p.deleteArtifacts(ctx, repos[i], int64(len(all[i])), artifacts)
+		p.progress.endRepo(repos[i].GetFullName(), deleted, size)
+		mu.Lock()
+		totalDeleted += deleted
+		totalSize += size
+		mu.Unlock()
+
+		return err
+	})
+
+	return totalDeleted, totalSize, err
+}
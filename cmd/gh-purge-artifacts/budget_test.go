@@ -0,0 +1,53 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+func TestOverBudget(t *testing.T) {
+	now := time.Now()
+	newArtifact := func(id, size int64, age time.Duration, expired bool) *artifact {
+		return &artifact{Artifact: github.Artifact{
+			ID:          github.Int64(id),
+			SizeInBytes: github.Int64(size),
+			CreatedAt:   &github.Timestamp{Time: now.Add(-age)},
+			Expired:     github.Bool(expired),
+		}}
+	}
+	all := []*artifact{
+		newArtifact(1, 100, 1*time.Hour, false),
+		newArtifact(2, 200, 3*time.Hour, false),
+		newArtifact(3, 300, 2*time.Hour, false),
+		newArtifact(4, 400, 4*time.Hour, true), // Expired artifacts don't count.
+		newArtifact(5, 500, 5*time.Hour, false),
+	}
+	ids := func(artifacts []*artifact) []int64 {
+		var ids []int64
+		for _, a := range artifacts {
+			ids = append(ids, a.GetID())
+		}
+		return ids
+	}
+
+	tests := []struct {
+		candidates []*artifact
+		budget     int64
+		want       []int64
+	}{
+		{all, 1100, nil},
+		{all, 1000, []int64{5}},
+		{all, 500, []int64{5, 2}},
+		{all, 0, []int64{5, 2, 3, 1}},
+		{all[:3], 500, []int64{2, 3, 1}}, // Not enough candidates.
+	}
+
+	for i, tt := range tests {
+		if want, got := tt.want, ids(overBudget(all, tt.candidates, tt.budget, now)); !reflect.DeepEqual(want, got) {
+			t.Errorf("%d: Expected %v got %v", i, want, got)
+		}
+	}
+}
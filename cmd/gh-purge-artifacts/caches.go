@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// actionsCache represents a GitHub Actions cache.
+type actionsCache struct {
+	ID             int64     `json:"id"`
+	Ref            string    `json:"ref"`
+	Key            string    `json:"key"`
+	Version        string    `json:"version"`
+	LastAccessedAt time.Time `json:"last_accessed_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	SizeInBytes    int64     `json:"size_in_bytes"`
+}
+
+// listCaches lists all Actions caches of the repository.
+func (p *purger) listCaches(ctx context.Context, owner, name string) ([]*actionsCache, error) {
+	var caches []*actionsCache
+	for page := 1; page != 0; {
+		u := fmt.Sprintf("repos/%s/%s/actions/caches?per_page=100&page=%d", owner, name, page)
+		req, err := p.gh.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		list := struct {
+			Caches []*actionsCache `json:"actions_caches"`
+		}{}
+		resp, err := p.gh.Do(ctx, req, &list)
+		if err != nil {
+			return nil, err
+		}
+
+		caches = append(caches, list.Caches...)
+		page = resp.NextPage
+	}
+
+	return caches, nil
+}
+
+// deleteCache deletes the Actions cache.
+func (p *purger) deleteCache(ctx context.Context, owner, name string, id int64) error {
+	req, err := p.gh.NewRequest(http.MethodDelete, fmt.Sprintf("repos/%s/%s/actions/caches/%d", owner, name, id), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.gh.Do(ctx, req, nil)
+	if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil // Already deleted e.g. by a retried request.
+	}
+
+	return err
+}
+
+// purgeRepoCaches purges Actions caches of the repository. Name patterns match
+// cache keys and the age is based on the time the cache was last accessed.
+func (p *purger) purgeRepoCaches(ctx context.Context, repo *github.Repository) (int64, int64, error) {
+	owner := repo.GetOwner().GetLogin()
+	name := repo.GetName()
+
+	caches, err := p.listCaches(ctx, owner, name)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var matched []*actionsCache
+	for _, cache := range caches {
+		if p.matchName(cache.Key) && p.match(cache.LastAccessedAt, cache.SizeInBytes) {
+			matched = append(matched, cache)
+		}
+	}
+
+	deleted, deletedSize, err := p.deleteItems(ctx, repo.GetFullName(), len(matched),
+		func(i int) int64 { return matched[i].SizeInBytes },
+		func(ctx context.Context, i int) error { return p.deleteCache(ctx, owner, name, matched[i].ID) },
+	)
+	p.report(repo.GetFullName(), deleted, int64(len(caches)), deletedSize)
+
+	return deleted, deletedSize, err
+}
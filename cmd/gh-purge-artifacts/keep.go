@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// item is an artifact or a workflow run.
+type item interface {
+	GetID() int64
+	GetCreatedAt() github.Timestamp
+}
+
+// keepLatest returns IDs of the most recent n items of each group.
+func keepLatest(items []item, n int, group func(item) string) map[int64]bool {
+	groups := map[string][]item{}
+	for _, i := range items {
+		key := group(i)
+		groups[key] = append(groups[key], i)
+	}
+
+	kept := map[int64]bool{}
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].GetCreatedAt().After(group[j].GetCreatedAt().Time)
+		})
+		for i := 0; i < n && i < len(group); i++ {
+			kept[group[i].GetID()] = true
+		}
+	}
+
+	return kept
+}
+
+// keep returns IDs of artifacts to keep. Artifacts are grouped
+// by the workflow that produced them and optionally by the name.
+func (p *purger) keep(ctx context.Context, owner, name string, artifacts []*artifact) (map[int64]bool, error) {
+	workflows := map[int64]string{} // Workflow IDs keyed by the run ID.
+	for _, a := range artifacts {
+		id := a.runID()
+		if _, ok := workflows[id]; ok || id == 0 {
+			continue
+		}
+
+		run, err := p.workflowRun(ctx, owner, name, id)
+		if err != nil {
+			return nil, err
+		}
+		if run != nil {
+			workflows[id] = strconv.FormatInt(run.GetWorkflowID(), 10)
+		} else {
+			workflows[id] = ""
+		}
+	}
+
+	items := make([]item, len(artifacts))
+	for i, a := range artifacts {
+		items[i] = a
+	}
+
+	return keepLatest(items, p.config.keep, func(i item) string {
+		a := i.(*artifact)
+		key := workflows[a.runID()]
+		if p.config.keepPerName {
+			key += "/" + a.GetName()
+		}
+		return key
+	}), nil
+}
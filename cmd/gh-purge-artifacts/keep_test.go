@@ -0,0 +1,40 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+func TestKeepLatest(t *testing.T) {
+	now := time.Now()
+	newArtifact := func(id int64, name string, age time.Duration) *artifact {
+		return &artifact{Artifact: github.Artifact{
+			ID:        github.Int64(id),
+			Name:      github.String(name),
+			CreatedAt: &github.Timestamp{Time: now.Add(-age)},
+		}}
+	}
+	artifacts := []item{
+		newArtifact(1, "build", 3*time.Hour),
+		newArtifact(2, "coverage", 2*time.Hour),
+		newArtifact(3, "build", time.Hour),
+		newArtifact(4, "coverage", 4*time.Hour),
+		newArtifact(5, "build", 5*time.Hour),
+	}
+
+	all := func(item) string { return "" }
+	if want, got := map[int64]bool{3: true, 2: true}, keepLatest(artifacts, 2, all); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected %v got %v", want, got)
+	}
+
+	byName := func(i item) string { return i.(*artifact).GetName() }
+	if want, got := map[int64]bool{3: true, 2: true}, keepLatest(artifacts, 1, byName); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected %v got %v", want, got)
+	}
+	if want, got := map[int64]bool{1: true, 2: true, 3: true, 4: true, 5: true}, keepLatest(artifacts, 3, byName); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected %v got %v", want, got)
+	}
+}
@@ -5,13 +5,18 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v32/github"
 	"github.com/pmatseykanets/gh-tools/auth"
+	"github.com/pmatseykanets/gh-tools/duration"
 	gh "github.com/pmatseykanets/gh-tools/github"
+	"github.com/pmatseykanets/gh-tools/pool"
 	"github.com/pmatseykanets/gh-tools/size"
 	"github.com/pmatseykanets/gh-tools/terminal"
 	"github.com/pmatseykanets/gh-tools/version"
@@ -26,12 +31,46 @@ Usage: gh-purge-artifacts [flags] [owner][/repo]
   repo          Repository name
 
 Flags:
-  -help         Print this information and exit
-  -dry-run      Dry run
-  -no-repo=     The pattern to reject repository names
-  -repo=        The pattern to match repository names
-  -token        Prompt for an Access Token
-  -version      Print the version and exit
+  -archived           Include archived repositories
+  -branch=            The pattern to match branches of workflow runs that produced artifacts
+  -caches             Purge Actions caches instead of artifacts
+  -closed-prs-only    Purge only artifacts of workflow runs of closed or merged pull requests
+  -concurrency=       The number of concurrent deletions per repository (default 4)
+  -help               Print this information and exit
+  -dry-run            Dry run
+  -exclude-expired    Skip expired artifacts that no longer occupy storage
+  -expired-only       Purge only expired artifacts
+  -interactive        Show what would be purged in each repository and ask for confirmation
+  -keep=              Keep the most recent n artifacts (or runs) per workflow
+                         (or tagged versions per package)
+  -keep-per-name      Keep the most recent n artifacts per workflow and artifact name
+  -name=              The pattern to match artifact names
+  -no-fork            Don't include fork repositories
+  -no-name=           The pattern to reject artifact names
+  -no-private         Don't include private repositories
+  -no-public          Don't include public repositories
+  -no-repo=           The pattern to reject repository names
+  -older-than=        Purge only artifacts older than the duration
+                         (e.g. 12h, 30d, 2w, 1y)
+  -packages           Purge tagged container package versions beyond -keep (and with -untagged
+                         untagged versions) instead of artifacts
+  -protect-open-prs   Skip artifacts of workflow runs of open pull requests
+  -quiet              Don't report progress to stderr
+  -release-assets     Purge assets attached to releases instead of artifacts
+  -repo=              The pattern to match repository names
+  -repo-concurrency=  The number of repositories purged concurrently (default 1).
+                         Up to -repo-concurrency times -concurrency deletions can be in flight
+  -runs               Purge completed workflow runs along with their artifacts and logs
+                         instead of artifacts
+  -size=              Purge only artifacts of the size [+-]<d><u>
+                         (e.g. +500mb, -1kb)
+  -target-size=       Purge the oldest artifacts until the storage used is under the size <d><u>
+                         (e.g. 10gb)
+  -target-size-total  Apply -target-size to all repositories combined rather than each one
+  -token              Prompt for an Access Token
+  -untagged           Purge untagged container package versions. Untagged versions can be
+                         platform images of tagged multi-arch images
+  -version            Print the version and exit
 `
 	fmt.Println(usage)
 }
@@ -44,19 +83,51 @@ func main() {
 }
 
 type config struct {
-	owner        string
-	repo         string
-	repoRegexp   *regexp.Regexp
-	dryRun       bool
-	token        bool           // Propmt for an access token.
-	noRepoRegexp *regexp.Regexp // The pattern to reject repository names.
+	owner           string
+	repo            string
+	repoRegexp      *regexp.Regexp
+	dryRun          bool
+	token           bool            // Propmt for an access token.
+	noRepoRegexp    *regexp.Regexp  // The pattern to reject repository names.
+	olderThan       time.Duration   // Purge only artifacts older than the duration.
+	nameRegexp      *regexp.Regexp  // The pattern to match artifact names.
+	noNameRegexp    *regexp.Regexp  // The pattern to reject artifact names.
+	size            *size.Predicate // Purge only artifacts of the size.
+	keep            int             // Keep the most recent artifacts per workflow.
+	keepPerName     bool            // Keep the most recent artifacts per workflow and name.
+	caches          bool            // Purge Actions caches instead of artifacts.
+	runs            bool            // Purge workflow runs instead of artifacts.
+	concurrency     int             // The number of concurrent deletions per repository.
+	repoConcurrency int             // The number of repositories purged concurrently.
+	interactive     bool            // Confirm deletions per repository.
+	noExpired       bool            // Skip expired artifacts.
+	expiredOnly     bool            // Purge only expired artifacts.
+	branchRegexp    *regexp.Regexp  // The pattern to match branches of workflow runs.
+	closedPRsOnly   bool            // Purge only artifacts of closed pull requests.
+	targetSize      int64           // The storage budget of each repository.
+	targetSizeTotal bool            // Apply the storage budget to all repositories combined.
+	archived        bool            // Include archived repositories.
+	noPrivate       bool            // Don't include private repositories.
+	noPublic        bool            // Don't include public repositories.
+	noFork          bool            // Don't include fork repositories.
+	quiet           bool            // Don't report progress.
+	releaseAssets   bool            // Purge release assets instead of artifacts.
+	packages        bool            // Purge container package versions instead of artifacts.
+	untagged        bool            // Purge untagged container package versions.
+	protectOpenPRs  bool            // Skip artifacts of open pull requests.
 }
 
 type purger struct {
-	gh     *github.Client
-	config config
-	stdout io.WriteCloser
-	stderr io.WriteCloser
+	gh       *github.Client
+	config   config
+	stdout   io.WriteCloser
+	stderr   io.WriteCloser
+	now      time.Time                     // The start time of the purge.
+	runs     map[int64]*github.WorkflowRun // Workflow runs keyed by the ID.
+	prs      map[string]string             // Pull request states keyed by owner/repo#number.
+	mu       sync.Mutex                    // Guards runs and the output.
+	progress *progress                     // The progress of the purge unless quiet.
+	packages []*containerPackage           // Container packages of the owner.
 }
 
 func readConfig() (config, error) {
@@ -65,18 +136,50 @@ func readConfig() (config, error) {
 		os.Exit(1)
 	}
 
-	config := config{}
+	config := config{
+		concurrency:     4,
+		repoConcurrency: 1,
+	}
 
 	var (
 		showVersion, showHelp bool
 		repo, noRepo          string
+		olderThan, name       string
+		noName, fsize         string
+		branch, targetSize    string
 		err                   error
 	)
+	flag.BoolVar(&config.archived, "archived", config.archived, "Include archived repositories")
+	flag.StringVar(&branch, "branch", "", "The pattern to match branches of workflow runs")
+	flag.BoolVar(&config.caches, "caches", config.caches, "Purge Actions caches instead of artifacts")
+	flag.BoolVar(&config.closedPRsOnly, "closed-prs-only", config.closedPRsOnly, "Purge only artifacts of closed pull requests")
+	flag.IntVar(&config.concurrency, "concurrency", config.concurrency, "The number of concurrent deletions per repository")
 	flag.BoolVar(&config.dryRun, "dry-run", config.dryRun, "Dry run")
+	flag.BoolVar(&config.noExpired, "exclude-expired", config.noExpired, "Skip expired artifacts")
+	flag.BoolVar(&config.expiredOnly, "expired-only", config.expiredOnly, "Purge only expired artifacts")
 	flag.BoolVar(&showHelp, "help", showHelp, "Print this information and exit")
+	flag.BoolVar(&config.interactive, "interactive", config.interactive, "Confirm purging of each repository")
+	flag.StringVar(&olderThan, "older-than", "", "Purge only artifacts older than the duration")
+	flag.IntVar(&config.keep, "keep", config.keep, "Keep the most recent n artifacts (or runs) per workflow")
+	flag.BoolVar(&config.keepPerName, "keep-per-name", config.keepPerName, "Keep the most recent artifacts per workflow and artifact name")
+	flag.StringVar(&name, "name", "", "The pattern to match artifact names")
+	flag.BoolVar(&config.noFork, "no-fork", config.noFork, "Don't include fork repositories")
+	flag.StringVar(&noName, "no-name", "", "The pattern to reject artifact names")
+	flag.BoolVar(&config.noPrivate, "no-private", config.noPrivate, "Don't include private repositories")
+	flag.BoolVar(&config.noPublic, "no-public", config.noPublic, "Don't include public repositories")
 	flag.StringVar(&noRepo, "no-repo", "", "The pattern to reject repository names")
+	flag.BoolVar(&config.packages, "packages", config.packages, "Purge container package versions instead of artifacts")
+	flag.BoolVar(&config.protectOpenPRs, "protect-open-prs", config.protectOpenPRs, "Skip artifacts of workflow runs of open pull requests")
+	flag.BoolVar(&config.quiet, "quiet", config.quiet, "Don't report progress")
+	flag.BoolVar(&config.releaseAssets, "release-assets", config.releaseAssets, "Purge release assets instead of artifacts")
 	flag.StringVar(&repo, "repo", "", "The pattern to match repository names")
+	flag.IntVar(&config.repoConcurrency, "repo-concurrency", config.repoConcurrency, "The number of repositories purged concurrently")
+	flag.StringVar(&fsize, "size", "", "Purge only artifacts of the size [+-]<d><u>")
+	flag.BoolVar(&config.runs, "runs", config.runs, "Purge workflow runs instead of artifacts")
+	flag.StringVar(&targetSize, "target-size", "", "Purge the oldest artifacts until the storage is under the size <d><u>")
+	flag.BoolVar(&config.targetSizeTotal, "target-size-total", config.targetSizeTotal, "Apply target-size to all repositories combined")
 	flag.BoolVar(&config.token, "token", config.token, "Prompt for Access Token")
+	flag.BoolVar(&config.untagged, "untagged", config.untagged, "Purge untagged container package versions")
 	flag.BoolVar(&showVersion, "version", showVersion, "Print version and exit")
 	flag.Usage = usage
 	flag.Parse()
@@ -120,6 +223,124 @@ func readConfig() (config, error) {
 		}
 	}
 
+	if config.noPrivate && config.noPublic {
+		return config, fmt.Errorf("no-private and no-public are mutually exclusive")
+	}
+
+	if config.concurrency <= 0 {
+		return config, fmt.Errorf("concurrency should be positive")
+	}
+
+	if config.repoConcurrency <= 0 {
+		return config, fmt.Errorf("repo-concurrency should be positive")
+	}
+
+	if config.interactive && config.dryRun {
+		return config, fmt.Errorf("interactive and dry-run are mutually exclusive")
+	}
+
+	if config.interactive && !terminal.IsTerminal(os.Stdin) {
+		return config, fmt.Errorf("interactive requires a terminal")
+	}
+
+	if config.keep < 0 {
+		return config, fmt.Errorf("keep should be positive")
+	}
+
+	if config.keepPerName && config.keep == 0 {
+		return config, fmt.Errorf("keep-per-name requires keep")
+	}
+
+	if config.caches && config.keep > 0 {
+		return config, fmt.Errorf("caches and keep are mutually exclusive")
+	}
+
+	if config.noExpired && config.expiredOnly {
+		return config, fmt.Errorf("exclude-expired and expired-only are mutually exclusive")
+	}
+
+	if config.caches && (config.noExpired || config.expiredOnly || branch != "" || config.closedPRsOnly || config.protectOpenPRs) {
+		return config, fmt.Errorf("caches can't be combined with exclude-expired, expired-only, branch, closed-prs-only and protect-open-prs")
+	}
+
+	if config.caches && config.runs {
+		return config, fmt.Errorf("caches and runs are mutually exclusive")
+	}
+
+	if config.releaseAssets && (config.caches || config.runs) {
+		return config, fmt.Errorf("release-assets can't be combined with caches and runs")
+	}
+
+	if config.releaseAssets && (config.keep > 0 || config.noExpired || config.expiredOnly || branch != "" || config.closedPRsOnly || config.protectOpenPRs) {
+		return config, fmt.Errorf("release-assets can only be combined with name, no-name, older-than and size")
+	}
+
+	if config.packages && (config.caches || config.runs || config.releaseAssets) {
+		return config, fmt.Errorf("packages can't be combined with caches, runs and release-assets")
+	}
+
+	if config.packages && (fsize != "" || config.keepPerName || config.noExpired || config.expiredOnly || branch != "" || config.closedPRsOnly || config.protectOpenPRs) {
+		return config, fmt.Errorf("packages can only be combined with keep, name, no-name and older-than")
+	}
+
+	if config.untagged && !config.packages {
+		return config, fmt.Errorf("untagged requires packages")
+	}
+
+	if config.packages && config.keep == 0 && !config.untagged {
+		return config, fmt.Errorf("packages requires keep or untagged")
+	}
+
+	if config.runs && (name != "" || noName != "" || fsize != "" || config.keepPerName || config.noExpired || config.expiredOnly || branch != "" || config.closedPRsOnly || config.protectOpenPRs) {
+		return config, fmt.Errorf("runs can only be combined with keep and older-than")
+	}
+
+	if name != "" {
+		if config.nameRegexp, err = regexp.Compile(name); err != nil {
+			return config, fmt.Errorf("invalid name pattern: %s", err)
+		}
+	}
+
+	if branch != "" {
+		if config.branchRegexp, err = regexp.Compile(branch); err != nil {
+			return config, fmt.Errorf("invalid branch pattern: %s", err)
+		}
+	}
+
+	if noName != "" {
+		if config.noNameRegexp, err = regexp.Compile(noName); err != nil {
+			return config, fmt.Errorf("invalid no-name pattern: %s", err)
+		}
+	}
+
+	if fsize != "" {
+		if config.size, err = size.ParsePredicate(fsize); err != nil {
+			return config, fmt.Errorf("invalid size: %s", err)
+		}
+	}
+
+	if targetSize != "" {
+		if config.targetSize, err = size.Parse(targetSize); err != nil {
+			return config, fmt.Errorf("invalid target-size: %s", err)
+		}
+		if config.targetSize <= 0 {
+			return config, fmt.Errorf("target-size should be positive")
+		}
+		if config.caches || config.runs || config.releaseAssets || config.packages {
+			return config, fmt.Errorf("target-size applies to artifacts only")
+		}
+	}
+
+	if config.targetSizeTotal && config.targetSize == 0 {
+		return config, fmt.Errorf("target-size-total requires target-size")
+	}
+
+	if olderThan != "" {
+		if config.olderThan, err = duration.Parse(olderThan); err != nil {
+			return config, fmt.Errorf("invalid older-than: %s", err)
+		}
+	}
+
 	return config, nil
 }
 
@@ -129,6 +350,9 @@ func run(ctx context.Context) error {
 	purger := &purger{
 		stdout: os.Stdout,
 		stderr: os.Stderr,
+		now:    time.Now(),
+		runs:   map[int64]*github.WorkflowRun{},
+		prs:    map[string]string{},
 	}
 	purger.config, err = readConfig()
 	if err != nil {
@@ -145,31 +369,69 @@ func run(ctx context.Context) error {
 		return fmt.Errorf("access token is required")
 	}
 
-	purger.gh = github.NewClient(oauth2.NewClient(ctx, oauth2.StaticTokenSource(
+	httpClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
-	)))
+	))
+	// Slow down as the rate limit budget drops and retry API calls that failed
+	// due to rate limiting or transient errors. Since each page is retried on its
+	// own listings resume at the failed page rather than start from scratch.
+	httpClient.Transport = gh.NewRetryTransport(gh.NewThrottleTransport(httpClient.Transport))
+	purger.gh = github.NewClient(httpClient)
 
 	return purger.purge(ctx)
 }
 
 func (p *purger) purge(ctx context.Context) error {
 	repos, err := gh.NewRepoFinder(p.gh).Find(ctx, gh.RepoFilter{
-		Owner:      p.config.owner,
-		Repo:       p.config.repo,
-		RepoRegexp: p.config.repoRegexp,
+		Owner:        p.config.owner,
+		Repo:         p.config.repo,
+		RepoRegexp:   p.config.repoRegexp,
+		NoRepoRegexp: p.config.noRepoRegexp,
+		Archived:     p.config.archived,
+		NoPrivate:    p.config.noPrivate,
+		NoPublic:     p.config.noPublic,
+		NoFork:       p.config.noFork,
 	})
 	if err != nil {
 		return err
 	}
 
-	var totalDeleted, totalSize int64
-	for _, repo := range repos {
-		deleted, size, err := p.purgeRepoArtifacts(ctx, repo)
-		if err != nil {
+	// Packages belong to the owner and are linked to repositories.
+	if p.config.packages && len(repos) > 0 {
+		if p.packages, err = p.listPackages(ctx, repos[0].GetOwner()); err != nil {
 			return err
 		}
-		totalDeleted += deleted
-		totalSize += size
+	}
+
+	var (
+		totalDeleted, totalSize int64
+		mu                      sync.Mutex
+	)
+	// Repositories are confirmed one at a time.
+	workers := p.config.repoConcurrency
+	if p.config.interactive {
+		workers = 1
+	}
+	if !p.config.quiet {
+		p.progress = newProgress(p.stderr, p.noun(), p.sizes(), len(repos))
+	}
+	if p.config.targetSizeTotal {
+		totalDeleted, totalSize, err = p.purgeTotalBudget(ctx, workers, repos)
+	} else {
+		err = pool.Run(ctx, workers, len(repos), func(ctx context.Context, i int) error {
+			p.progress.startRepo(repos[i].GetFullName())
+			deleted, size, err := p.purgeRepo(ctx, repos[i])
+			p.progress.endRepo(repos[i].GetFullName(), deleted, size)
+			mu.Lock()
+			totalDeleted += deleted
+			totalSize += size
+			mu.Unlock()
+
+			return err
+		})
+	}
+	if err != nil {
+		return err
 	}
 
 	if totalRepos := len(repos); totalRepos > 1 {
@@ -179,57 +441,194 @@ func (p *purger) purge(ctx context.Context) error {
 		} else {
 			fmt.Fprintf(p.stdout, " purged")
 		}
-		fmt.Fprintf(p.stdout, " %d artifacts (%s) in %d repos\n", totalDeleted, size.FormatBytes(totalSize), totalRepos)
+		fmt.Fprintf(p.stdout, " %d %s", totalDeleted, p.noun())
+		if p.sizes() {
+			fmt.Fprintf(p.stdout, " (%s)", size.FormatBytes(totalSize))
+		}
+		fmt.Fprintf(p.stdout, " in %d repos\n", totalRepos)
 	}
 
 	return nil
 }
 
+// purgeRepo purges the repository in the configured mode.
+// It returns the number and the total size of purged items.
+func (p *purger) purgeRepo(ctx context.Context, repo *github.Repository) (int64, int64, error) {
+	switch {
+	case p.config.caches:
+		return p.purgeRepoCaches(ctx, repo)
+	case p.config.runs:
+		return p.purgeRepoRuns(ctx, repo)
+	case p.config.releaseAssets:
+		return p.purgeRepoReleaseAssets(ctx, repo)
+	case p.config.packages:
+		return p.purgeRepoPackages(ctx, repo)
+	}
+
+	return p.purgeRepoArtifacts(ctx, repo)
+}
+
+// noun returns the name of items purged in the configured mode.
+func (p *purger) noun() string {
+	switch {
+	case p.config.caches:
+		return "caches"
+	case p.config.runs:
+		return "workflow runs"
+	case p.config.releaseAssets:
+		return "release assets"
+	case p.config.packages:
+		return "package versions"
+	}
+
+	return "artifacts"
+}
+
+// sizes reports whether sizes of items purged in the configured mode are known.
+func (p *purger) sizes() bool {
+	return !p.config.runs && !p.config.packages
+}
+
+// confirm shows what is about to be purged in the repository
+// and asks for a confirmation.
+func (p *purger) confirm(repo string, n, itemsSize int64) (bool, error) {
+	prompt := fmt.Sprintf("%s: purge %d %s", repo, n, p.noun())
+	if p.sizes() {
+		prompt += fmt.Sprintf(" (%s)", size.FormatBytes(itemsSize))
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return terminal.Confirm(prompt + "? [y/N] ")
+}
+
+// report writes the result of purging the repository.
+// Repositories are purged concurrently so the line is written at once.
+func (p *purger) report(repo string, deleted, total, deletedSize int64) {
+	line := repo
+	if deleted > 0 {
+		if p.config.dryRun {
+			line += " found"
+		} else {
+			line += " purged"
+		}
+		line += fmt.Sprintf(" %d out of %d %s", deleted, total, p.noun())
+		if p.sizes() {
+			line += fmt.Sprintf(" (%s)", size.FormatBytes(deletedSize))
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintln(p.stdout, line)
+}
+
 func (p *purger) purgeRepoArtifacts(ctx context.Context, repo *github.Repository) (int64, int64, error) {
+	all, artifacts, err := p.artifactCandidates(ctx, repo)
+	if err != nil {
+		return 0, 0, err
+	}
+	if p.config.targetSize > 0 {
+		artifacts = overBudget(all, artifacts, p.config.targetSize, p.now)
+	}
+
+	return p.deleteArtifacts(ctx, repo, int64(len(all)), artifacts)
+}
+
+// artifactCandidates returns all artifacts of the repository
+// and the ones matching the filters.
+func (p *purger) artifactCandidates(ctx context.Context, repo *github.Repository) ([]*artifact, []*artifact, error) {
 	owner := repo.GetOwner().GetLogin()
 	name := repo.GetName()
 
-	var artifacts []*github.Artifact
-	opt := &github.ListOptions{PerPage: 30}
-	for {
-		list, resp, err := p.gh.Actions.ListArtifacts(ctx, owner, name, opt)
-		if err != nil {
-			return 0, 0, err
-		}
-
-		artifacts = append(artifacts, list.Artifacts...)
+	all, err := p.listArtifacts(ctx, owner, name)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		if resp.NextPage == 0 {
-			break
+	artifacts := p.filter(all, func(a *artifact) bool { return p.matchName(a.GetName()) && p.matchExpired(a) })
+	if p.config.branchRegexp != nil || p.config.closedPRsOnly || p.config.protectOpenPRs {
+		if artifacts, err = p.filterRuns(ctx, owner, name, artifacts); err != nil {
+			return nil, nil, err
+		}
+	}
+	if p.config.keep > 0 {
+		kept, err := p.keep(ctx, owner, name, artifacts)
+		if err != nil {
+			return nil, nil, err
 		}
-		opt.Page = resp.NextPage
+		artifacts = p.filter(artifacts, func(a *artifact) bool { return !kept[a.GetID()] })
 	}
+	artifacts = p.filter(artifacts, func(a *artifact) bool { return p.match(a.GetCreatedAt().Time, a.GetSizeInBytes()) })
 
-	fmt.Fprintf(p.stdout, "%s/%s", owner, name)
+	return all, artifacts, nil
+}
+
+// deleteArtifacts deletes artifacts of the repository and reports the result.
+func (p *purger) deleteArtifacts(ctx context.Context, repo *github.Repository, total int64, artifacts []*artifact) (int64, int64, error) {
+	owner := repo.GetOwner().GetLogin()
+	name := repo.GetName()
 
-	var deleted, deletedSize int64
-	defer func() {
-		if deleted > 0 {
-			if p.config.dryRun {
-				fmt.Fprintf(p.stdout, " found")
-			} else {
-				fmt.Fprintf(p.stdout, " purged")
+	deleted, deletedSize, err := p.deleteItems(ctx, repo.GetFullName(), len(artifacts),
+		func(i int) int64 { return artifacts[i].GetSizeInBytes() },
+		func(ctx context.Context, i int) error {
+			resp, err := p.gh.Actions.DeleteArtifact(ctx, owner, name, artifacts[i].GetID())
+			if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+				return nil // Already deleted e.g. by a retried request.
 			}
-			fmt.Fprintf(p.stdout, " %d out of %d artifacts (%s)", len(artifacts), deleted, size.FormatBytes(deletedSize))
-		}
-		fmt.Fprintln(p.stdout)
-	}()
+			return err
+		},
+	)
+	p.report(repo.GetFullName(), deleted, total, deletedSize)
+
+	return deleted, deletedSize, err
+}
+
+// filter returns artifacts matching the predicate.
+func (p *purger) filter(artifacts []*artifact, match func(*artifact) bool) []*artifact {
+	var filtered []*artifact
 	for _, artifact := range artifacts {
-		if !p.config.dryRun {
-			_, err := p.gh.Actions.DeleteArtifact(ctx, owner, name, artifact.GetID())
-			if err != nil {
-				return 0, 0, err
-			}
+		if match(artifact) {
+			filtered = append(filtered, artifact)
 		}
+	}
+
+	return filtered
+}
+
+// matchName reports whether the name matches the name patterns.
+func (p *purger) matchName(name string) bool {
+	if p.config.nameRegexp != nil && !p.config.nameRegexp.MatchString(name) {
+		return false
+	}
+	if p.config.noNameRegexp != nil && p.config.noNameRegexp.MatchString(name) {
+		return false
+	}
+
+	return true
+}
+
+// matchExpired reports whether the artifact passes the expiration filters.
+func (p *purger) matchExpired(a *artifact) bool {
+	switch {
+	case p.config.noExpired:
+		return !a.expired(p.now)
+	case p.config.expiredOnly:
+		return a.expired(p.now)
+	}
+
+	return true
+}
 
-		deleted++
-		deletedSize += artifact.GetSizeInBytes()
+// match reports whether an item of the age and the size should be purged.
+func (p *purger) match(createdAt time.Time, itemSize int64) bool {
+	if p.config.olderThan > 0 && p.now.Sub(createdAt) < p.config.olderThan {
+		return false
+	}
+	if p.config.size != nil && !p.config.size.Match(itemSize) {
+		return false
 	}
 
-	return deleted, deletedSize, nil
+	return true
 }
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// containerPackage represents a container package in GitHub Packages.
+type containerPackage struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Repository *struct {
+		FullName string `json:"full_name"`
+	} `json:"repository,omitempty"`
+}
+
+// repo returns the full name of the repository the package is linked to if any.
+func (p *containerPackage) repo() string {
+	if p.Repository == nil {
+		return ""
+	}
+
+	return p.Repository.FullName
+}
+
+// packageVersion represents a version of a container package.
+type packageVersion struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"` // The digest.
+	CreatedAt time.Time `json:"created_at"`
+	Metadata  struct {
+		Container struct {
+			Tags []string `json:"tags"`
+		} `json:"container"`
+	} `json:"metadata"`
+}
+
+func (v *packageVersion) GetID() int64 {
+	return v.ID
+}
+
+func (v *packageVersion) GetCreatedAt() github.Timestamp {
+	return github.Timestamp{Time: v.CreatedAt}
+}
+
+func (v *packageVersion) tagged() bool {
+	return len(v.Metadata.Container.Tags) > 0
+}
+
+// packagesURL returns the URL of container packages of the owner.
+func packagesURL(owner *github.User) string {
+	if owner.GetType() == "Organization" {
+		return fmt.Sprintf("orgs/%s/packages", owner.GetLogin())
+	}
+
+	return fmt.Sprintf("users/%s/packages", owner.GetLogin())
+}
+
+// listPackages lists all container packages of the owner.
+func (p *purger) listPackages(ctx context.Context, owner *github.User) ([]*containerPackage, error) {
+	var packages []*containerPackage
+	for page := 1; page != 0; {
+		u := fmt.Sprintf("%s?package_type=container&per_page=100&page=%d", packagesURL(owner), page)
+		req, err := p.gh.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var list []*containerPackage
+		resp, err := p.gh.Do(ctx, req, &list)
+		if err != nil {
+			return nil, err
+		}
+
+		packages = append(packages, list...)
+		page = resp.NextPage
+	}
+
+	return packages, nil
+}
+
+// listPackageVersions lists all versions of the container package.
+func (p *purger) listPackageVersions(ctx context.Context, owner *github.User, name string) ([]*packageVersion, error) {
+	var versions []*packageVersion
+	for page := 1; page != 0; {
+		u := fmt.Sprintf("%s/container/%s/versions?per_page=100&page=%d", packagesURL(owner), url.PathEscape(name), page)
+		req, err := p.gh.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var list []*packageVersion
+		resp, err := p.gh.Do(ctx, req, &list)
+		if err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, list...)
+		page = resp.NextPage
+	}
+
+	return versions, nil
+}
+
+// deletePackageVersion deletes the version of the container package.
+func (p *purger) deletePackageVersion(ctx context.Context, owner *github.User, name string, id int64) error {
+	u := fmt.Sprintf("%s/container/%s/versions/%d", packagesURL(owner), url.PathEscape(name), id)
+	req, err := p.gh.NewRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.gh.Do(ctx, req, nil)
+	if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil // Already deleted e.g. by a retried request.
+	}
+
+	return err
+}
+
+// packageCandidates returns, if untagged is set, untagged versions and, if keep
+// is positive, tagged versions other than the most recent keep ones.
+// Untagged versions are opt-in since platform images of multi-arch images are
+// stored as untagged versions referenced only by the tagged image index.
+func packageCandidates(versions []*packageVersion, keep int, untagged bool) []*packageVersion {
+	var (
+		candidates []*packageVersion
+		tagged     []item
+	)
+	for _, v := range versions {
+		if v.tagged() {
+			tagged = append(tagged, v)
+		} else if untagged {
+			candidates = append(candidates, v)
+		}
+	}
+	if keep == 0 {
+		return candidates
+	}
+
+	kept := keepLatest(tagged, keep, func(item) string { return "" })
+	for _, v := range tagged {
+		if !kept[v.GetID()] {
+			candidates = append(candidates, v.(*packageVersion))
+		}
+	}
+
+	return candidates
+}
+
+// purgeRepoPackages purges versions of container packages linked to the repository.
+// Name patterns match package names.
+func (p *purger) purgeRepoPackages(ctx context.Context, repo *github.Repository) (int64, int64, error) {
+	type packageVersionRef struct {
+		pkg     string
+		version *packageVersion
+	}
+
+	var (
+		total   int64
+		matched []packageVersionRef
+	)
+	for _, pkg := range p.packages {
+		if !strings.EqualFold(pkg.repo(), repo.GetFullName()) || !p.matchName(pkg.Name) {
+			continue
+		}
+
+		versions, err := p.listPackageVersions(ctx, repo.GetOwner(), pkg.Name)
+		if err != nil {
+			return 0, 0, err
+		}
+		total += int64(len(versions))
+
+		for _, v := range packageCandidates(versions, p.config.keep, p.config.untagged) {
+			if p.match(v.CreatedAt, 0) {
+				matched = append(matched, packageVersionRef{pkg: pkg.Name, version: v})
+			}
+		}
+	}
+
+	deleted, _, err := p.deleteItems(ctx, repo.GetFullName(), len(matched),
+		func(i int) int64 { return 0 },
+		func(ctx context.Context, i int) error {
+			return p.deletePackageVersion(ctx, repo.GetOwner(), matched[i].pkg, matched[i].version.ID)
+		},
+	)
+	p.report(repo.GetFullName(), deleted, total, 0)
+
+	return deleted, 0, err
+}
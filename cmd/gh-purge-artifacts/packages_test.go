@@ -0,0 +1,50 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPackageCandidates(t *testing.T) {
+	now := time.Now()
+	newVersion := func(id int64, age time.Duration, tags ...string) *packageVersion {
+		v := &packageVersion{ID: id, CreatedAt: now.Add(-age)}
+		v.Metadata.Container.Tags = tags
+		return v
+	}
+	versions := []*packageVersion{
+		newVersion(1, 1*time.Hour, "latest", "v3"),
+		newVersion(2, 2*time.Hour),
+		newVersion(3, 3*time.Hour, "v2"),
+		newVersion(4, 4*time.Hour),
+		newVersion(5, 5*time.Hour, "v1"),
+	}
+	ids := func(versions []*packageVersion) []int64 {
+		var ids []int64
+		for _, v := range versions {
+			ids = append(ids, v.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		keep     int
+		untagged bool
+		want     []int64
+	}{
+		{0, true, []int64{2, 4}},
+		{1, true, []int64{2, 4, 3, 5}},
+		{2, true, []int64{2, 4, 5}},
+		{5, true, []int64{2, 4}},
+		{0, false, nil},
+		{1, false, []int64{3, 5}},
+		{5, false, nil},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.want, ids(packageCandidates(versions, tt.keep, tt.untagged)); !reflect.DeepEqual(want, got) {
+			t.Errorf("keep %d untagged %t: Expected %v got %v", tt.keep, tt.untagged, want, got)
+		}
+	}
+}
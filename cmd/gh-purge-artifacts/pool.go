@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+
+	"github.com/pmatseykanets/gh-tools/pool"
+)
+
+// deleteItems deletes n items of the repository in parallel unless it's a dry run
+// or the deletion hasn't been confirmed. It returns the number and the total size
+// of deleted items.
+func (p *purger) deleteItems(ctx context.Context, repo string, n int, itemSize func(i int) int64, del func(ctx context.Context, i int) error) (int64, int64, error) {
+	if p.config.interactive && !p.config.dryRun && n > 0 {
+		var total int64
+		for i := 0; i < n; i++ {
+			total += itemSize(i)
+		}
+		ok, err := p.confirm(repo, int64(n), total)
+		if err != nil || !ok {
+			return 0, 0, err
+		}
+	}
+
+	deleted := make([]bool, n)
+	var err error
+	if p.config.dryRun {
+		for i := range deleted {
+			deleted[i] = true
+		}
+	} else {
+		err = pool.Run(ctx, p.config.concurrency, n, func(ctx context.Context, i int) error {
+			if err := del(ctx, i); err != nil {
+				return err
+			}
+			deleted[i] = true
+			return nil
+		})
+	}
+
+	var count, total int64
+	for i := range deleted {
+		if deleted[i] {
+			count++
+			total += itemSize(i)
+		}
+	}
+
+	return count, total, err
+}
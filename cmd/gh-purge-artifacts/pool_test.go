@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDeleteItems(t *testing.T) {
+	sizes := []int64{1, 2, 3, 4}
+	itemSize := func(i int) int64 { return sizes[i] }
+
+	p := &purger{config: config{concurrency: 2}}
+	deleted, deletedSize, err := p.deleteItems(context.Background(), "owner/repo", len(sizes), itemSize, func(ctx context.Context, i int) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 4 || deletedSize != 10 {
+		t.Errorf("Expected 4 items (10) got %d (%d)", deleted, deletedSize)
+	}
+
+	p.config.dryRun = true
+	deleted, deletedSize, err = p.deleteItems(context.Background(), "owner/repo", len(sizes), itemSize, func(ctx context.Context, i int) error {
+		t.Fatal("Unexpected delete in a dry run")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 4 || deletedSize != 10 {
+		t.Errorf("Expected 4 items (10) got %d (%d)", deleted, deletedSize)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pmatseykanets/gh-tools/size"
+)
+
+// progress reports the progress of the purge with running totals and an ETA.
+// All methods are no-op on a nil progress so that the purge doesn't have to
+// check whether the progress is reported.
+type progress struct {
+	w     io.Writer
+	noun  string // The name of purged items.
+	sizes bool   // Whether to report sizes.
+	repos int    // The total number of repositories.
+	start time.Time
+
+	mu      sync.Mutex
+	started int   // The number of started repositories.
+	done    int   // The number of completed repositories.
+	deleted int64 // The number of items purged so far.
+	size    int64 // The size of items purged so far.
+}
+
+func newProgress(w io.Writer, noun string, sizes bool, repos int) *progress {
+	return &progress{
+		w:     w,
+		noun:  noun,
+		sizes: sizes,
+		repos: repos,
+		start: time.Now(),
+	}
+}
+
+// startRepo reports that purging of the repository has started.
+func (p *progress) startRepo(repo string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.started++
+	fmt.Fprintf(p.w, "[%d/%d] %s\n", p.started, p.repos, repo)
+}
+
+// endRepo reports that purging of the repository has completed
+// along with running totals and the estimated time left.
+func (p *progress) endRepo(repo string, deleted, deletedSize int64) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done++
+	p.deleted += deleted
+	p.size += deletedSize
+
+	line := fmt.Sprintf("[%d/%d] %s: done, %d %s", p.done, p.repos, repo, p.deleted, p.noun)
+	if p.sizes {
+		line += fmt.Sprintf(" (%s)", size.FormatBytes(p.size))
+	}
+	line += " so far"
+	if p.done < p.repos {
+		line += fmt.Sprintf(", ETA %s", eta(time.Since(p.start), p.done, p.repos))
+	}
+	fmt.Fprintln(p.w, line)
+}
+
+// eta estimates the time left to complete total repositories
+// based on the average time spent on done ones.
+func eta(elapsed time.Duration, done, total int) time.Duration {
+	if done <= 0 || done >= total {
+		return 0
+	}
+
+	return (elapsed / time.Duration(done) * time.Duration(total-done)).Round(time.Second)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestETA(t *testing.T) {
+	tests := []struct {
+		elapsed     time.Duration
+		done, total int
+		want        time.Duration
+	}{
+		{time.Minute, 0, 10, 0},
+		{time.Minute, 1, 10, 9 * time.Minute},
+		{time.Minute, 4, 10, 90 * time.Second},
+		{time.Minute, 10, 10, 0},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.want, eta(tt.elapsed, tt.done, tt.total); want != got {
+			t.Errorf("%s %d/%d: Expected %s got %s", tt.elapsed, tt.done, tt.total, want, got)
+		}
+	}
+}
+
+func TestProgress(t *testing.T) {
+	var buf bytes.Buffer
+	p := newProgress(&buf, "artifacts", true, 2)
+	p.startRepo("owner/a")
+	p.endRepo("owner/a", 2, 2048)
+	p.startRepo("owner/b")
+	p.endRepo("owner/b", 1, 1024)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if want, got := 4, len(lines); want != got {
+		t.Fatalf("Expected %d lines got %d: %s", want, got, buf.String())
+	}
+	if want, got := "[1/2] owner/a", string(lines[0]); want != got {
+		t.Errorf("Expected %q got %q", want, got)
+	}
+	if want, got := "[2/2] owner/b: done, 3 artifacts (3.1 kB) so far", string(lines[3]); want != got {
+		t.Errorf("Expected %q got %q", want, got)
+	}
+
+	var nilProgress *progress
+	nilProgress.startRepo("owner/a")
+	nilProgress.endRepo("owner/a", 1, 1)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// listRuns lists all workflow runs of the repository.
+func (p *purger) listRuns(ctx context.Context, owner, name string) ([]*github.WorkflowRun, error) {
+	var runs []*github.WorkflowRun
+	opts := &github.ListWorkflowRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		list, resp, err := p.gh.Actions.ListRepositoryWorkflowRuns(ctx, owner, name, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		runs = append(runs, list.WorkflowRuns...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return runs, nil
+}
+
+// deleteRun deletes the workflow run along with its artifacts and logs.
+func (p *purger) deleteRun(ctx context.Context, owner, name string, id int64) error {
+	req, err := p.gh.NewRequest(http.MethodDelete, fmt.Sprintf("repos/%s/%s/actions/runs/%d", owner, name, id), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.gh.Do(ctx, req, nil)
+	if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil // Already deleted e.g. by a retried request.
+	}
+
+	return err
+}
+
+// runCandidates returns completed runs other than, if keep is positive, the most
+// recent keep completed ones per workflow. Runs in progress or queued are neither
+// purged nor counted towards keep.
+func runCandidates(runs []*github.WorkflowRun, keep int) []*github.WorkflowRun {
+	var completed []item
+	for _, run := range runs {
+		if run.GetStatus() == "completed" {
+			completed = append(completed, run)
+		}
+	}
+
+	var kept map[int64]bool
+	if keep > 0 {
+		kept = keepLatest(completed, keep, func(i item) string {
+			return strconv.FormatInt(i.(*github.WorkflowRun).GetWorkflowID(), 10)
+		})
+	}
+
+	var candidates []*github.WorkflowRun
+	for _, run := range completed {
+		if !kept[run.GetID()] {
+			candidates = append(candidates, run.(*github.WorkflowRun))
+		}
+	}
+
+	return candidates
+}
+
+// purgeRepoRuns purges completed workflow runs of the repository.
+func (p *purger) purgeRepoRuns(ctx context.Context, repo *github.Repository) (int64, int64, error) {
+	owner := repo.GetOwner().GetLogin()
+	name := repo.GetName()
+
+	runs, err := p.listRuns(ctx, owner, name)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var matched []*github.WorkflowRun
+	for _, run := range runCandidates(runs, p.config.keep) {
+		if p.match(run.GetCreatedAt().Time, 0) {
+			matched = append(matched, run)
+		}
+	}
+
+	deleted, _, err := p.deleteItems(ctx, repo.GetFullName(), len(matched),
+		func(i int) int64 { return 0 },
+		func(ctx context.Context, i int) error { return p.deleteRun(ctx, owner, name, matched[i].GetID()) },
+	)
+	p.report(repo.GetFullName(), deleted, int64(len(runs)), 0)
+
+	return deleted, 0, err
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+func TestRunCandidates(t *testing.T) {
+	now := time.Now()
+	newRun := func(id, workflowID int64, status string, age time.Duration) *github.WorkflowRun {
+		return &github.WorkflowRun{
+			ID:         github.Int64(id),
+			WorkflowID: github.Int64(workflowID),
+			Status:     github.String(status),
+			CreatedAt:  &github.Timestamp{Time: now.Add(-age)},
+		}
+	}
+	runs := []*github.WorkflowRun{
+		newRun(1, 10, "in_progress", time.Minute),
+		newRun(2, 10, "completed", time.Hour),
+		newRun(3, 10, "completed", 2*time.Hour),
+		newRun(4, 20, "queued", time.Minute),
+		newRun(5, 20, "completed", 3*time.Hour),
+	}
+	ids := func(runs []*github.WorkflowRun) []int64 {
+		var ids []int64
+		for _, run := range runs {
+			ids = append(ids, run.GetID())
+		}
+		return ids
+	}
+
+	tests := []struct {
+		keep int
+		want []int64
+	}{
+		{0, []int64{2, 3, 5}},
+		{1, []int64{3}},
+		{2, nil},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.want, ids(runCandidates(runs, tt.keep)); !reflect.DeepEqual(want, got) {
+			t.Errorf("keep %d: Expected %v got %v", tt.keep, want, got)
+		}
+	}
+}
@@ -8,10 +8,13 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v32/github"
 	"github.com/pmatseykanets/gh-tools/auth"
 	gh "github.com/pmatseykanets/gh-tools/github"
+	"github.com/pmatseykanets/gh-tools/pool"
 	"github.com/pmatseykanets/gh-tools/terminal"
 	"github.com/pmatseykanets/gh-tools/version"
 	"golang.org/x/oauth2"
@@ -21,17 +24,30 @@ func usage() {
 	usage := `Manage notification subscriptions across GitHub repositories
 
 Usage: gh-watch [flags] [owner][/repo]
+       gh-watch -mine [flags] [owner]
   owner         Repository owner (user or organization)
   repo          Repository name
 
 Flags:
-  -help         Print this information and exit
-  -no-repo=     The pattern to reject repository names
-  -repo=        The pattern to match repository names
-  -token        Prompt for an Access Token
-  -unwatch      Unsubscribe from repository notifications
-  -version      Print the version and exit
-  -watch        Subscribe to repository notifications
+  -archived       Include archived repositories
+  -archived-only  Include only archived repositories
+  -concurrency=   The number of repositories processed concurrently (default 4)
+  -help           Print this information and exit
+  -keep=          The pattern to match repository names to keep watching with -unwatch
+  -mark-read      Mark notifications from the repositories as read
+  -mine           Operate on repositories you watch across all owners
+  -no-fork        Don't include fork repositories
+  -no-private     Don't include private repositories
+  -no-public      Don't include public repositories
+  -no-repo=       The pattern to reject repository names
+  -repo=          The pattern to match repository names
+  -status=        Show only repositories with the subscription status
+                     (watching, ignoring, not-watching)
+  -sync=          Reconcile subscriptions with the desired statuses in the YAML file
+  -token          Prompt for an Access Token
+  -unwatch        Unsubscribe from repository notifications
+  -version        Print the version and exit
+  -watch          Subscribe to repository notifications
 `
 	fmt.Println(usage)
 }
@@ -51,6 +67,17 @@ type config struct {
 	noRepoRegexp *regexp.Regexp // The pattern to reject repository names.
 	watch        bool           // Subscribe to repository notifications.
 	unwatch      bool           // Unsubscribe from repository notifications.
+	status       string         // Show only repositories with the subscription status.
+	mine         bool           // Operate on repositories watched by the user.
+	syncFile     string         // The file with the desired subscription statuses.
+	keepRegexp   *regexp.Regexp // The pattern to match repository names to keep watching.
+	concurrency  int            // The number of repositories processed concurrently.
+	archived     bool           // Include archived repositories.
+	archivedOnly bool           // Include only archived repositories.
+	noPrivate    bool           // Don't include private repositories.
+	noPublic     bool           // Don't include public repositories.
+	noFork       bool           // Don't include fork repositories.
+	markRead     bool           // Mark notifications as read.
 }
 
 type subscriber struct {
@@ -58,6 +85,8 @@ type subscriber struct {
 	config config
 	stdout io.WriteCloser
 	stderr io.WriteCloser
+	rules  []*syncRule // Sync rules if requested.
+	now    time.Time   // The start time of the run.
 }
 
 func readConfig() (config, error) {
@@ -66,16 +95,30 @@ func readConfig() (config, error) {
 		os.Exit(1)
 	}
 
-	config := config{}
+	config := config{
+		concurrency: 4,
+	}
 
 	var (
 		showVersion, showHelp bool
-		repo, noRepo          string
+		repo, noRepo, status  string
+		keep                  string
 		err                   error
 	)
+	flag.BoolVar(&config.archived, "archived", config.archived, "Include archived repositories")
+	flag.BoolVar(&config.archivedOnly, "archived-only", config.archivedOnly, "Include only archived repositories")
+	flag.IntVar(&config.concurrency, "concurrency", config.concurrency, "The number of repositories processed concurrently")
 	flag.BoolVar(&showHelp, "help", showHelp, "Print this information and exit")
+	flag.StringVar(&keep, "keep", "", "The pattern to match repository names to keep watching")
+	flag.BoolVar(&config.markRead, "mark-read", config.markRead, "Mark notifications as read")
+	flag.BoolVar(&config.mine, "mine", config.mine, "Operate on repositories you watch across all owners")
+	flag.BoolVar(&config.noFork, "no-fork", config.noFork, "Don't include fork repositories")
+	flag.BoolVar(&config.noPrivate, "no-private", config.noPrivate, "Don't include private repositories")
+	flag.BoolVar(&config.noPublic, "no-public", config.noPublic, "Don't include public repositories")
 	flag.StringVar(&noRepo, "no-repo", "", "The pattern to reject repository names")
 	flag.StringVar(&repo, "repo", "", "The pattern to match repository names")
+	flag.StringVar(&config.syncFile, "sync", "", "Reconcile subscriptions with the desired statuses in the file")
+	flag.StringVar(&status, "status", "", "Show only repositories with the subscription status")
 	flag.BoolVar(&config.token, "token", config.token, "Prompt for Access Token")
 	flag.BoolVar(&config.unwatch, "unwatch", config.unwatch, "Unsubscribe from repository notifications")
 	flag.BoolVar(&showVersion, "version", showVersion, "Print version and exit")
@@ -106,10 +149,14 @@ func readConfig() (config, error) {
 		return config, fmt.Errorf("invalid owner or repository name %s", flag.Arg(0))
 	}
 
-	if config.owner == "" {
+	if config.owner == "" && !config.mine {
 		return config, fmt.Errorf("owner is required")
 	}
 
+	if config.mine && config.repo != "" {
+		return config, fmt.Errorf("mine can't be combined with a repository name")
+	}
+
 	if repo != "" {
 		config.repoRegexp, err = regexp.Compile(repo)
 		if err != nil {
@@ -123,6 +170,36 @@ func readConfig() (config, error) {
 		}
 	}
 
+	if config.noPrivate && config.noPublic {
+		return config, fmt.Errorf("no-private and no-public are mutually exclusive")
+	}
+
+	if config.concurrency <= 0 {
+		return config, fmt.Errorf("concurrency should be positive")
+	}
+
+	if keep != "" {
+		if !config.unwatch {
+			return config, fmt.Errorf("keep requires unwatch")
+		}
+		if config.keepRegexp, err = regexp.Compile(keep); err != nil {
+			return config, fmt.Errorf("invalid keep pattern: %s", err)
+		}
+	}
+
+	if config.syncFile != "" && (config.watch || config.unwatch) {
+		return config, fmt.Errorf("sync can't be combined with watch and unwatch")
+	}
+
+	switch status {
+	case "", "watching", "ignoring":
+		config.status = status
+	case "not-watching":
+		config.status = "not watching"
+	default:
+		return config, fmt.Errorf("invalid status: %s", status)
+	}
+
 	return config, nil
 }
 
@@ -132,12 +209,19 @@ func run(ctx context.Context) error {
 	subscriber := &subscriber{
 		stdout: os.Stdout,
 		stderr: os.Stderr,
+		now:    time.Now(),
 	}
 	subscriber.config, err = readConfig()
 	if err != nil {
 		return err
 	}
 
+	if subscriber.config.syncFile != "" {
+		if subscriber.rules, err = readSyncFile(subscriber.config.syncFile); err != nil {
+			return fmt.Errorf("can't read sync file: %s", err)
+		}
+	}
+
 	var token string
 	if subscriber.config.token {
 		token, _ = terminal.PasswordPrompt("Access Token: ")
@@ -167,53 +251,114 @@ func subscriptionStatus(sub *github.Subscription) string {
 	return "watching"
 }
 
+// keep reports whether the repository should be kept watched when unwatching.
+func (w *subscriber) keep(repo *github.Repository) bool {
+	return w.config.keepRegexp != nil && w.config.keepRegexp.MatchString(repo.GetName())
+}
+
 func (w *subscriber) run(ctx context.Context) error {
-	repos, err := gh.NewRepoFinder(w.gh).Find(ctx, gh.RepoFilter{
-		Owner:      w.config.owner,
-		Repo:       w.config.repo,
-		RepoRegexp: w.config.repoRegexp,
-	})
+	filter := gh.RepoFilter{
+		Owner:        w.config.owner,
+		Repo:         w.config.repo,
+		RepoRegexp:   w.config.repoRegexp,
+		NoRepoRegexp: w.config.noRepoRegexp,
+		Archived:     w.config.archived || w.config.archivedOnly,
+		NoPrivate:    w.config.noPrivate,
+		NoPublic:     w.config.noPublic,
+		NoFork:       w.config.noFork,
+	}
+	var (
+		repos []*github.Repository
+		err   error
+	)
+	if w.config.mine {
+		repos, err = gh.NewRepoFinder(w.gh).Watched(ctx, filter)
+	} else {
+		repos, err = gh.NewRepoFinder(w.gh).Find(ctx, filter)
+	}
 	if err != nil {
 		return err
 	}
+	if w.config.archivedOnly {
+		var archived []*github.Repository
+		for _, repo := range repos {
+			if repo.GetArchived() {
+				archived = append(archived, repo)
+			}
+		}
+		repos = archived
+	}
 
-	for _, repo := range repos {
-		fmt.Fprint(w.stdout, repo.GetFullName())
-
-		// Get the current subscription for the repo.
-		sub, _, err := w.gh.Activity.GetRepositorySubscription(ctx, w.config.owner, repo.GetName())
+	// Repositories are processed concurrently but listed in order.
+	var (
+		lines = make([]string, len(repos))
+		done  = make([]bool, len(repos))
+		next  int
+		mu    sync.Mutex
+	)
+	return pool.Run(ctx, w.config.concurrency, len(repos), func(ctx context.Context, i int) error {
+		line, err := w.apply(ctx, repos[i])
 		if err != nil {
-			fmt.Fprintln(w.stdout)
-			return err
+			return fmt.Errorf("%s: %w", repos[i].GetFullName(), err)
 		}
 
-		// List the current subscription status.
-		fmt.Fprint(w.stdout, " ", subscriptionStatus(sub))
-
-		switch {
-		case w.config.watch && !sub.GetSubscribed():
-			sub, _, err = w.gh.Activity.SetRepositorySubscription(ctx, w.config.owner, repo.GetName(), &github.Subscription{
-				Subscribed: github.Bool(true),
-			})
-			if err != nil {
-				fmt.Fprintln(w.stdout)
-				return err
+		mu.Lock()
+		defer mu.Unlock()
+		lines[i], done[i] = line, true
+		for ; next < len(repos) && done[next]; next++ {
+			if lines[next] != "" {
+				fmt.Fprintln(w.stdout, lines[next])
 			}
+		}
 
-			fmt.Fprint(w.stdout, " -> ", subscriptionStatus(sub))
-		case w.config.unwatch && sub.GetSubscribed():
-			_, err = w.gh.Activity.DeleteRepositorySubscription(ctx, w.config.owner, repo.GetName())
-			if err != nil {
-				fmt.Fprintln(w.stdout)
-				return err
-			}
-			sub = nil
+		return nil
+	})
+}
+
+// apply applies the requested change to the subscription of the repository.
+// It returns the subscription status line or an empty string if the repository
+// is filtered out by the status.
+func (w *subscriber) apply(ctx context.Context, repo *github.Repository) (string, error) {
+	// Get the current subscription for the repo.
+	sub, _, err := w.gh.Activity.GetRepositorySubscription(ctx, repo.GetOwner().GetLogin(), repo.GetName())
+	if err != nil {
+		return "", err
+	}
+
+	status := subscriptionStatus(sub)
+	if w.config.status != "" && status != w.config.status {
+		return "", nil
+	}
 
-			fmt.Fprint(w.stdout, " -> ", subscriptionStatus(sub))
+	// List the current subscription status.
+	line := repo.GetFullName() + " " + status
+
+	var desired string
+	switch {
+	case w.config.watch && !sub.GetSubscribed():
+		desired = "watching"
+	case w.config.unwatch && sub.GetSubscribed() && !w.keep(repo):
+		desired = "not watching"
+	case w.rules != nil:
+		if d := desiredStatus(w.rules, repo.GetFullName()); d != status {
+			desired = d
 		}
+	}
+	if desired != "" {
+		if sub, err = w.setStatus(ctx, repo, desired); err != nil {
+			return "", err
+		}
+		line += " -> " + subscriptionStatus(sub)
+	}
 
-		fmt.Fprintln(w.stdout)
+	if w.config.markRead {
+		// Notifications received after the start of the run are left unread.
+		_, err = w.gh.Activity.MarkRepositoryNotificationsRead(ctx, repo.GetOwner().GetLogin(), repo.GetName(), w.now)
+		if err != nil {
+			return "", err
+		}
+		line += ", notifications marked as read"
 	}
 
-	return nil
+	return line, nil
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+
+	"github.com/google/go-github/v32/github"
+	"gopkg.in/yaml.v2"
+)
+
+// syncRule maps repositories matching the pattern to the desired subscription status.
+type syncRule struct {
+	Pattern string `yaml:"pattern"` // The pattern to match full repository names (owner/repo).
+	State   string `yaml:"state"`   // watch, ignore or unwatch.
+	re      *regexp.Regexp
+	status  string
+}
+
+// syncStates maps states of sync rules to subscription statuses.
+var syncStates = map[string]string{
+	"watch":   "watching",
+	"ignore":  "ignoring",
+	"unwatch": "not watching",
+}
+
+// readSyncFile reads sync rules from the file.
+func readSyncFile(path string) ([]*syncRule, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSyncRules(data)
+}
+
+// parseSyncRules parses sync rules e.g.
+//   - pattern: '^owner/api-'
+//     state: watch
+//   - pattern: '^owner/'
+//     state: unwatch
+func parseSyncRules(data []byte) ([]*syncRule, error) {
+	var rules []*syncRule
+	if err := yaml.UnmarshalStrict(data, &rules); err != nil {
+		return nil, err
+	}
+
+	for i, rule := range rules {
+		var err error
+		if rule.re, err = regexp.Compile(rule.Pattern); err != nil {
+			return nil, fmt.Errorf("rule %d: invalid pattern: %s", i+1, err)
+		}
+		var ok bool
+		if rule.status, ok = syncStates[rule.State]; !ok {
+			return nil, fmt.Errorf("rule %d: invalid state: %s", i+1, rule.State)
+		}
+	}
+
+	return rules, nil
+}
+
+// desiredStatus returns the subscription status of the first rule matching
+// the repository or an empty string if there is none.
+func desiredStatus(rules []*syncRule, repo string) string {
+	for _, rule := range rules {
+		if rule.re.MatchString(repo) {
+			return rule.status
+		}
+	}
+
+	return ""
+}
+
+// setStatus changes the subscription status of the repository.
+func (w *subscriber) setStatus(ctx context.Context, repo *github.Repository, status string) (*github.Subscription, error) {
+	owner, name := repo.GetOwner().GetLogin(), repo.GetName()
+
+	switch status {
+	case "watching":
+		sub, _, err := w.gh.Activity.SetRepositorySubscription(ctx, owner, name, &github.Subscription{
+			Subscribed: github.Bool(true),
+		})
+		return sub, err
+	case "ignoring":
+		sub, _, err := w.gh.Activity.SetRepositorySubscription(ctx, owner, name, &github.Subscription{
+			Ignored: github.Bool(true),
+		})
+		return sub, err
+	}
+
+	_, err := w.gh.Activity.DeleteRepositorySubscription(ctx, owner, name)
+
+	return nil, err
+}
@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestParseSyncRules(t *testing.T) {
+	rules, err := parseSyncRules([]byte(`
+- pattern: '^foo/api-'
+  state: watch
+- pattern: '^foo/legacy-'
+  state: ignore
+- pattern: '^foo/'
+  state: unwatch
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		repo string
+		want string
+	}{
+		{"foo/api-users", "watching"},
+		{"foo/legacy-billing", "ignoring"},
+		{"foo/docs", "not watching"},
+		{"bar/api-users", ""},
+	}
+
+	for _, tt := range tests {
+		if want, got := tt.want, desiredStatus(rules, tt.repo); want != got {
+			t.Errorf("%s: Expected %q got %q", tt.repo, want, got)
+		}
+	}
+}
+
+func TestParseSyncRulesErrors(t *testing.T) {
+	for _, data := range []string{
+		"- pattern: '^foo/'\n  state: mute\n",
+		"- pattern: '['\n  state: watch\n",
+		"- pattern: '^foo/'\n  status: watch\n",
+		"pattern: '^foo/'\n",
+	} {
+		if _, err := parseSyncRules([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %q", data)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package duration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		in  string
+		d   time.Duration
+		err bool
+	}{
+		{"30d", 30 * day, false},
+		{"2w", 14 * day, false},
+		{"1y", 365 * day, false},
+		{"12h", 12 * time.Hour, false},
+		{"", 0, true},
+		{"d", 0, true},
+		{"-1d", 0, true},
+		{"-1h", 0, true},
+		{"1x", 0, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			d, err := Parse(tt.in)
+			if tt.err {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tt.d, d; want != got {
+				t.Errorf("Expected %s got %s", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package github
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CacheTransport is an http.RoundTripper that caches responses to GET requests
+// on disk and revalidates them with conditional If-None-Match requests.
+// GitHub doesn't count 304 Not Modified responses against the rate limit.
+type CacheTransport struct {
+	Transport http.RoundTripper // The underlying transport. http.DefaultTransport if nil.
+	Dir       string            // The cache directory.
+}
+
+// cacheEntry represents a cached response.
+type cacheEntry struct {
+	ETag   string      `json:"etag"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// NewCacheTransport creates a new CacheTransport instance.
+func NewCacheTransport(transport http.RoundTripper, dir string) *CacheTransport {
+	return &CacheTransport{
+		Transport: transport,
+		Dir:       dir,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return transport.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	entry := t.read(key)
+	if entry != nil {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		resp.Body.Close()
+		header := entry.Header.Clone()
+		// Keep the current rate limit.
+		for name, values := range resp.Header {
+			if strings.HasPrefix(name, "X-Ratelimit-") {
+				header[name] = values
+			}
+		}
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader(entry.Body)),
+			ContentLength: int64(len(entry.Body)),
+			Request:       req,
+		}, nil
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		// The cache is an optimization. Failing to write it is not an error.
+		_ = t.write(key, &cacheEntry{ETag: resp.Header.Get("ETag"), Header: resp.Header, Body: body})
+	}
+
+	return resp, nil
+}
+
+// cacheKey returns the cache key of the request. Credentials are part of the key
+// so that responses are not shared between different access tokens.
+func cacheKey(req *http.Request) string {
+	hash := sha256.New()
+	for _, s := range []string{req.URL.String(), req.Header.Get("Accept"), req.Header.Get("Authorization")} {
+		hash.Write([]byte(s))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// read returns the cached response or nil if there is none.
+func (t *CacheTransport) read(key string) *cacheEntry {
+	data, err := ioutil.ReadFile(filepath.Join(t.Dir, key))
+	if err != nil {
+		return nil
+	}
+
+	entry := &cacheEntry{}
+	if err = json.Unmarshal(data, entry); err != nil || entry.ETag == "" {
+		return nil
+	}
+
+	return entry
+}
+
+// write atomically writes the response to the cache.
+func (t *CacheTransport) write(key string, entry *cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(t.Dir, 0755); err != nil {
+		return err
+	}
+
+	// Write to a temp file first so that an interrupted run doesn't leave a partial cache entry.
+	file, err := ioutil.TempFile(t.Dir, ".response")
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err = file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return os.Rename(file.Name(), filepath.Join(t.Dir, key))
+}
@@ -0,0 +1,74 @@
+package github
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCacheTransport(t *testing.T) {
+	var (
+		calls, notModified int
+		body               = "v1"
+		etag               = `"1"`
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewCacheTransport(nil, t.TempDir())}
+	get := func() (string, *http.Response) {
+		resp, err := client.Get(server.URL + "/repos/foo/bar/contents/go.mod")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		contents, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(contents), resp
+	}
+
+	for i, want := range []string{"v1", "v1", "v1"} {
+		got, resp := get()
+		if want != got {
+			t.Errorf("%d: expected body %q got %q", i, want, got)
+		}
+		if want, got := http.StatusOK, resp.StatusCode; want != got {
+			t.Errorf("%d: expected status %d got %d", i, want, got)
+		}
+		if want, got := "42", resp.Header.Get("X-RateLimit-Remaining"); want != got {
+			t.Errorf("%d: expected remaining %s got %s", i, want, got)
+		}
+	}
+	if want, got := 3, calls; want != got {
+		t.Errorf("Expected %d calls got %d", want, got)
+	}
+	if want, got := 2, notModified; want != got {
+		t.Errorf("Expected %d not modified responses got %d", want, got)
+	}
+
+	// The resource changed.
+	body, etag = "v2", `"2"`
+	if got, _ := get(); got != "v2" {
+		t.Errorf("Expected body %q got %q", "v2", got)
+	}
+	if got, _ := get(); got != "v2" {
+		t.Errorf("Expected body %q got %q", "v2", got)
+	}
+	if want, got := 3, notModified; want != got {
+		t.Errorf("Expected %d not modified responses got %d", want, got)
+	}
+}
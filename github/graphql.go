@@ -0,0 +1,60 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// GraphQLError represents an error returned by the GitHub GraphQL API.
+type GraphQLError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// GraphQLErrors represents errors returned by the GitHub GraphQL API.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// GraphQL executes the GraphQL query with variables using the client
+// and decodes the data into result.
+func GraphQL(ctx context.Context, client *github.Client, query string, variables map[string]interface{}, result interface{}) error {
+	req, err := client.NewRequest("POST", "graphql", map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors GraphQLErrors   `json:"errors"`
+	}
+	_, err = client.Do(ctx, req, &resp)
+	if err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Errors
+	}
+
+	if result == nil || len(resp.Data) == 0 {
+		return nil
+	}
+	if err = json.Unmarshal(resp.Data, result); err != nil {
+		return fmt.Errorf("can't decode GraphQL response: %s", err)
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/google/go-github/v32/github"
 )
@@ -20,14 +21,17 @@ func NewRepoFinder(client *github.Client) *RepoFinder {
 
 // RepoFilter represents criteria used to filter repositories.
 type RepoFilter struct {
-	Owner        string         // The owner name. Can be a user or an organization.
-	Repo         string         // The repository name when in single-repo mode.
-	RepoRegexp   *regexp.Regexp // The pattern to match repository names.
-	Archived     bool           // Include archived repositories.
-	NoPrivate    bool           // Don't inlucde private repositories.
-	NoPublic     bool           // Don't include public repositories.
-	NoFork       bool           // Don't include forks.
-	NoRepoRegexp *regexp.Regexp // The pattern to reject repository names.
+	Owner         string         // The owner name. Can be a user or an organization.
+	Repo          string         // The repository name when in single-repo mode.
+	RepoRegexp    *regexp.Regexp // The pattern to match repository names.
+	Archived      bool           // Include archived repositories.
+	NoPrivate     bool           // Don't inlucde private repositories.
+	NoPublic      bool           // Don't include public repositories.
+	NoFork        bool           // Don't include forks.
+	NoRepoRegexp  *regexp.Regexp // The pattern to reject repository names.
+	DefaultBranch string         // The default branch name.
+	Topics        []string       // Only include repositories with all of the topics.
+	Languages     []string       // Only include repositories with one of the primary languages.
 }
 
 // Find repositories using a given filter.
@@ -63,6 +67,36 @@ func (f *RepoFinder) Find(ctx context.Context, filter RepoFilter) ([]*github.Rep
 	return repos, err
 }
 
+// Watched finds repositories watched by the authenticated user using a given filter.
+// Repositories of all owners are included unless the filter has the owner.
+func (f *RepoFinder) Watched(ctx context.Context, filter RepoFilter) ([]*github.Repository, error) {
+	if filter.NoPrivate && filter.NoPublic {
+		return nil, nil // Nothing to do.
+	}
+
+	opts := listOptions
+	var filtered []*github.Repository
+	for {
+		repos, resp, err := f.Client.Activity.ListWatched(ctx, "", &opts)
+		if err != nil {
+			return nil, fmt.Errorf("can't read watched repositories: %s", err)
+		}
+
+		for _, repo := range apply(repos, filter) {
+			if filter.Owner == "" || strings.EqualFold(repo.GetOwner().GetLogin(), filter.Owner) {
+				filtered = append(filtered, repo)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return filtered, nil
+}
+
 var listOptions = github.ListOptions{PerPage: 100}
 
 func (f *RepoFinder) userRepos(ctx context.Context, filter RepoFilter) ([]*github.Repository, error) {
@@ -116,6 +150,12 @@ func (f *RepoFinder) orgRepos(ctx context.Context, filter RepoFilter) ([]*github
 	return filtered, nil
 }
 
+// Filter returns repositories matching the filter e.g. ones read from a repo list file.
+// The owner and the repository name of the filter are ignored.
+func Filter(repos []*github.Repository, filter RepoFilter) []*github.Repository {
+	return apply(repos, filter)
+}
+
 func apply(repos []*github.Repository, filter RepoFilter) []*github.Repository {
 	var (
 		filtered = make([]*github.Repository, len(repos))
@@ -148,6 +188,18 @@ func apply(repos []*github.Repository, filter RepoFilter) []*github.Repository {
 			continue
 		}
 
+		if filter.DefaultBranch != "" && repo.GetDefaultBranch() != filter.DefaultBranch {
+			continue
+		}
+
+		if !hasTopics(repo, filter.Topics) {
+			continue
+		}
+
+		if !hasLanguage(repo, filter.Languages) {
+			continue
+		}
+
 		filtered[n] = repo
 		n++
 	}
@@ -158,3 +210,36 @@ func apply(repos []*github.Repository, filter RepoFilter) []*github.Repository {
 
 	return filtered[:n]
 }
+
+// hasTopics checks if the repository has all of the topics.
+func hasTopics(repo *github.Repository, topics []string) bool {
+	for _, topic := range topics {
+		var found bool
+		for _, t := range repo.Topics {
+			if strings.EqualFold(t, topic) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasLanguage checks if the primary language of the repository is one of the languages.
+func hasLanguage(repo *github.Repository, languages []string) bool {
+	if len(languages) == 0 {
+		return true
+	}
+
+	for _, language := range languages {
+		if strings.EqualFold(repo.GetLanguage(), language) {
+			return true
+		}
+	}
+
+	return false
+}
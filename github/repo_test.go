@@ -112,6 +112,47 @@ func TestApply(t *testing.T) {
 				{Name: stringp("bar"), Archived: boolp(true)},
 			},
 		},
+		{
+			desc: "default branch",
+			in: []*github.Repository{
+				{Name: stringp("foo"), DefaultBranch: stringp("main")},
+				{Name: stringp("bar"), DefaultBranch: stringp("master")},
+			},
+			filter: RepoFilter{
+				DefaultBranch: "master",
+			},
+			out: []*github.Repository{
+				{Name: stringp("bar"), DefaultBranch: stringp("master")},
+			},
+		},
+		{
+			desc: "topics",
+			in: []*github.Repository{
+				{Name: stringp("foo"), Topics: []string{"go", "api"}},
+				{Name: stringp("bar"), Topics: []string{"go"}},
+				{Name: stringp("baz")},
+			},
+			filter: RepoFilter{
+				Topics: []string{"API", "go"},
+			},
+			out: []*github.Repository{
+				{Name: stringp("foo"), Topics: []string{"go", "api"}},
+			},
+		},
+		{
+			desc: "languages",
+			in: []*github.Repository{
+				{Name: stringp("foo"), Language: stringp("Go")},
+				{Name: stringp("bar"), Language: stringp("Python")},
+				{Name: stringp("baz")},
+			},
+			filter: RepoFilter{
+				Languages: []string{"go", "ruby"},
+			},
+			out: []*github.Repository{
+				{Name: stringp("foo"), Language: stringp("Go")},
+			},
+		},
 		{
 			desc: "no matches",
 			in: []*github.Repository{
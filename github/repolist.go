@@ -0,0 +1,83 @@
+package github
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// ReadRepoList reads full repository names in the form owner/repo, one per line.
+// Empty lines and lines starting with # are ignored. Anything after
+// the repository name separated by whitespace is ignored as well.
+// Duplicate names are returned once.
+func ReadRepoList(r io.Reader) ([]string, error) {
+	var (
+		names   []string
+		seen    = map[string]bool{}
+		scanner = bufio.NewScanner(r)
+		lineNo  int
+	)
+	for scanner.Scan() {
+		lineNo++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		name := fields[0]
+		parts := strings.Split(name, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("line %d: invalid repository name %s", lineNo, name)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// ReadRepoListFile reads full repository names from the file.
+// The path - reads from stdin.
+func ReadRepoListFile(path string) ([]string, error) {
+	if path == "-" {
+		return ReadRepoList(os.Stdin)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadRepoList(file)
+}
+
+// Get repositories by full names in the form owner/repo.
+func (f *RepoFinder) Get(ctx context.Context, names []string) ([]*github.Repository, error) {
+	repos := make([]*github.Repository, 0, len(names))
+	for _, name := range names {
+		parts := strings.SplitN(name, "/", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid repository name %s", name)
+		}
+
+		repo, _, err := f.Client.Repositories.Get(ctx, parts[0], parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("can't read repository %s: %s", name, err)
+		}
+		repos = append(repos, repo)
+	}
+
+	return repos, nil
+}
@@ -0,0 +1,78 @@
+package github
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadRepoList(t *testing.T) {
+	tests := []struct {
+		desc  string
+		in    string
+		names []string
+		err   bool
+	}{
+		{
+			desc: "empty",
+			in:   "",
+		},
+		{
+			desc:  "names",
+			in:    "foo/bar\nfoo/baz\n",
+			names: []string{"foo/bar", "foo/baz"},
+		},
+		{
+			desc:  "comments and empty lines",
+			in:    "# repos\n\n  foo/bar  \n\nqux/baz",
+			names: []string{"foo/bar", "qux/baz"},
+		},
+		{
+			desc:  "trailing fields",
+			in:    "foo/bar https://github.com/foo/bar/pull/1\n",
+			names: []string{"foo/bar"},
+		},
+		{
+			desc:  "duplicates",
+			in:    "foo/bar a.go\nfoo/bar b.go\nfoo/baz\nfoo/bar\n",
+			names: []string{"foo/bar", "foo/baz"},
+		},
+		{
+			desc: "no owner",
+			in:   "bar\n",
+			err:  true,
+		},
+		{
+			desc: "too many parts",
+			in:   "foo/bar/baz\n",
+			err:  true,
+		},
+		{
+			desc: "empty repo",
+			in:   "foo/\n",
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			names, err := ReadRepoList(strings.NewReader(tt.in))
+			if tt.err {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if want, got := tt.names, names; !reflect.DeepEqual(want, got) {
+				t.Errorf("Expected %v got %v", want, got)
+			}
+		})
+	}
+}
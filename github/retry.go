@@ -0,0 +1,185 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryTransport is an http.RoundTripper that retries requests that failed
+// due to rate limiting or transient errors using exponential backoff.
+// Transient errors are retried only for idempotent methods since a non-idempotent
+// request (e.g. creating a PR) may have been applied before the failure.
+//
+// It honors the Retry-After header of secondary rate limit responses
+// and waits for the rate limit reset when the primary rate limit is exhausted.
+type RetryTransport struct {
+	Transport  http.RoundTripper // The underlying transport. http.DefaultTransport if nil.
+	MaxRetries int               // The maximum number of retries.
+	MinBackoff time.Duration     // The initial backoff.
+	MaxBackoff time.Duration     // The maximum backoff.
+}
+
+// NewRetryTransport creates a new RetryTransport instance with sane defaults.
+func NewRetryTransport(transport http.RoundTripper) *RetryTransport {
+	return &RetryTransport{
+		Transport:  transport,
+		MaxRetries: 5,
+		MinBackoff: time.Second,
+		MaxBackoff: time.Minute,
+	}
+}
+
+// secondaryRateLimitBackoff is the minimum time to wait after hitting
+// the secondary rate limit when GitHub doesn't provide Retry-After.
+const secondaryRateLimitBackoff = time.Minute
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := transport.RoundTrip(req)
+
+		if attempt >= t.MaxRetries {
+			return resp, err
+		}
+
+		wait, retry := t.shouldRetry(attempt, req.Method, resp, err)
+		if !retry {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		if err := Sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// shouldRetry decides if the request should be retried and how long to wait.
+func (t *RetryTransport) shouldRetry(attempt int, method string, resp *http.Response, err error) (time.Duration, bool) {
+	backoff := Backoff(attempt, t.MinBackoff, t.MaxBackoff)
+
+	if err != nil {
+		return backoff, idempotent(method) // Transient network error.
+	}
+
+	switch resp.StatusCode {
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		if wait, ok := retryAfter(resp); ok {
+			return wait, true
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			// The primary rate limit is exhausted. Wait until it resets.
+			if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+				if wait := time.Until(time.Unix(reset, 0)); wait > 0 {
+					return wait + time.Second, true
+				}
+				return backoff, true
+			}
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || isSecondaryRateLimit(resp) {
+			if backoff < secondaryRateLimitBackoff {
+				backoff = secondaryRateLimitBackoff
+			}
+			return backoff, true
+		}
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return backoff, idempotent(method)
+	}
+
+	return 0, false
+}
+
+// idempotent checks if requests with the method can be safely retried.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// retryAfter returns the duration from the Retry-After header if any.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// isSecondaryRateLimit checks if the response is a secondary (abuse) rate limit error.
+// The body is restored so that it can be read by the caller.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	message := strings.ToLower(string(body))
+	return strings.Contains(message, "secondary rate limit") || strings.Contains(message, "abuse")
+}
+
+// Backoff returns the exponential backoff with jitter for the attempt.
+func Backoff(attempt int, min, max time.Duration) time.Duration {
+	backoff := min
+	for i := 0; i < attempt && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	// Add up to 10% of jitter.
+	return backoff + time.Duration(rand.Int63n(int64(backoff)/10+1))
+}
+
+// Sleep pauses for the duration d or until the context is done.
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
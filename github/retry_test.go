@@ -0,0 +1,147 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		desc     string
+		method   string
+		statuses []int
+		headers  http.Header
+		body     string
+		calls    int
+		status   int
+	}{
+		{
+			desc:     "success",
+			statuses: []int{http.StatusOK},
+			calls:    1,
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "not found is not retried",
+			statuses: []int{http.StatusNotFound},
+			calls:    1,
+			status:   http.StatusNotFound,
+		},
+		{
+			desc:     "forbidden is not retried",
+			statuses: []int{http.StatusForbidden},
+			calls:    1,
+			status:   http.StatusForbidden,
+		},
+		{
+			desc:     "server errors",
+			statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			calls:    3,
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "post server errors are not retried",
+			method:   http.MethodPost,
+			statuses: []int{http.StatusBadGateway, http.StatusOK},
+			calls:    1,
+			status:   http.StatusBadGateway,
+		},
+		{
+			desc:     "retry after",
+			statuses: []int{http.StatusForbidden, http.StatusOK},
+			headers:  http.Header{"Retry-After": []string{"0"}},
+			calls:    2,
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "primary rate limit",
+			statuses: []int{http.StatusForbidden, http.StatusOK},
+			headers:  http.Header{"X-Ratelimit-Remaining": []string{"0"}, "X-Ratelimit-Reset": []string{"0"}},
+			calls:    2,
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "post rate limit",
+			method:   http.MethodPost,
+			statuses: []int{http.StatusTooManyRequests, http.StatusOK},
+			headers:  http.Header{"Retry-After": []string{"0"}},
+			calls:    2,
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "max retries",
+			statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			calls:    3,
+			status:   http.StatusBadGateway,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[calls]
+				calls++
+				if status != http.StatusOK {
+					for k, v := range tt.headers {
+						w.Header()[k] = v
+					}
+				}
+				w.WriteHeader(status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: &RetryTransport{
+				MaxRetries: 2,
+				MinBackoff: time.Millisecond,
+				MaxBackoff: 2 * time.Millisecond,
+			}}
+			method := tt.method
+			if method == "" {
+				method = http.MethodPut
+			}
+			req, err := http.NewRequest(method, server.URL, strings.NewReader("body"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if want, got := tt.status, resp.StatusCode; want != got {
+				t.Errorf("Expected status %d got %d", want, got)
+			}
+			if want, got := tt.calls, calls; want != got {
+				t.Errorf("Expected %d calls got %d", want, got)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		min     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{10, time.Minute},
+	}
+
+	for _, tt := range tests {
+		got := Backoff(tt.attempt, time.Second, time.Minute)
+		if got < tt.min || got > tt.min+tt.min/10 {
+			t.Errorf("Attempt %d: expected backoff within [%s, %s] got %s", tt.attempt, tt.min, tt.min+tt.min/10, got)
+		}
+	}
+}
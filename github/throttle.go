@@ -0,0 +1,114 @@
+package github
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ThrottleTransport is an http.RoundTripper that tracks rate limits reported
+// in X-RateLimit headers and pre-emptively slows down once the remaining budget
+// drops below the threshold by spreading the remaining requests evenly until
+// the rate limit resets.
+type ThrottleTransport struct {
+	Transport http.RoundTripper // The underlying transport. http.DefaultTransport if nil.
+	Threshold float64           // The fraction of the rate limit left below which requests are slowed down.
+
+	mu     sync.Mutex
+	limits map[string]rateLimit // Rate limits by resource.
+}
+
+// rateLimit represents the state of a rate limit.
+type rateLimit struct {
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// NewThrottleTransport creates a new ThrottleTransport instance with sane defaults.
+func NewThrottleTransport(transport http.RoundTripper) *ThrottleTransport {
+	return &ThrottleTransport{
+		Transport: transport,
+		Threshold: 0.1,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ThrottleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	if err := Sleep(req.Context(), t.Delay(req)); err != nil {
+		return nil, err
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.update(req, resp)
+
+	return resp, nil
+}
+
+// Delay returns how long to wait before sending the request.
+func (t *ThrottleTransport) Delay(req *http.Request) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	limit, ok := t.limits[rateLimitResource(req)]
+	if !ok || limit.limit <= 0 || float64(limit.remaining) >= float64(limit.limit)*t.Threshold {
+		return 0
+	}
+
+	wait := time.Until(limit.reset)
+	if wait <= 0 {
+		return 0
+	}
+
+	return wait / time.Duration(limit.remaining+1)
+}
+
+// update records the rate limit reported in the response headers if any.
+func (t *ThrottleTransport) update(req *http.Request, resp *http.Response) {
+	limit, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.limits == nil {
+		t.limits = make(map[string]rateLimit)
+	}
+	t.limits[rateLimitResource(req)] = rateLimit{
+		limit:     limit,
+		remaining: remaining,
+		reset:     time.Unix(reset, 0),
+	}
+}
+
+// rateLimitResource returns the rate limit resource the request counts against.
+func rateLimitResource(req *http.Request) string {
+	switch path := req.URL.Path; {
+	case strings.Contains(path, "/search/"):
+		return "search"
+	case strings.HasSuffix(path, "/graphql"):
+		return "graphql"
+	default:
+		return "core"
+	}
+}
@@ -0,0 +1,88 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestThrottleTransport(t *testing.T) {
+	var (
+		remaining int
+		reset     = time.Now().Add(time.Hour)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/core" {
+			w.Header().Set("X-RateLimit-Limit", "100")
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		}
+	}))
+	defer server.Close()
+
+	transport := NewThrottleTransport(nil)
+	client := &http.Client{Transport: transport}
+	request := func(path string) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	get := func(path string) {
+		resp, err := client.Do(request(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	// Nothing is known about the rate limit yet.
+	if want, got := time.Duration(0), transport.Delay(request("/core")); want != got {
+		t.Errorf("Expected delay %s got %s", want, got)
+	}
+
+	// Plenty of budget left.
+	remaining = 50
+	get("/core")
+	if want, got := time.Duration(0), transport.Delay(request("/core")); want != got {
+		t.Errorf("Expected delay %s got %s", want, got)
+	}
+
+	// The budget is running out. Remaining requests are spread until the reset.
+	remaining = 9
+	get("/core")
+	if got := transport.Delay(request("/core")); got <= 5*time.Minute || got > 6*time.Minute {
+		t.Errorf("Expected delay of about 6m got %s", got)
+	}
+
+	// Other resources are tracked separately.
+	if want, got := time.Duration(0), transport.Delay(request("/search/code")); want != got {
+		t.Errorf("Expected delay %s got %s", want, got)
+	}
+}
+
+func TestRateLimitResource(t *testing.T) {
+	tests := []struct {
+		path     string
+		resource string
+	}{
+		{"/repos/foo/bar", "core"},
+		{"/search/code", "search"},
+		{"/api/v3/search/repositories", "search"},
+		{"/graphql", "graphql"},
+		{"/api/graphql", "graphql"},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, "https://api.github.com"+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := tt.resource, rateLimitResource(req); want != got {
+			t.Errorf("%s: expected %s got %s", tt.path, want, got)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	var (
+		seen                = make([]int32, 10)
+		running, maxRunning int32
+	)
+	err := Run(context.Background(), 3, len(seen), func(ctx context.Context, i int) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		atomic.AddInt32(&seen[i], 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range seen {
+		if seen[i] != 1 {
+			t.Errorf("Expected item %d to be processed once got %d", i, seen[i])
+		}
+	}
+	if maxRunning > 3 {
+		t.Errorf("Expected at most 3 concurrent calls got %d", maxRunning)
+	}
+}
+
+func TestRunError(t *testing.T) {
+	errFailed := errors.New("failed")
+	var calls int32
+	err := Run(context.Background(), 1, 10, func(ctx context.Context, i int) error {
+		atomic.AddInt32(&calls, 1)
+		if i == 2 {
+			return errFailed
+		}
+		return nil
+	})
+	if !errors.Is(err, errFailed) {
+		t.Errorf("Expected %v got %v", errFailed, err)
+	}
+	if got := atomic.LoadInt32(&calls); got > 4 {
+		t.Errorf("Expected the pool to stop after the error, got %d calls", got)
+	}
+}
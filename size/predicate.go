@@ -0,0 +1,49 @@
+package size
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Predicate matches sizes against a value.
+type Predicate struct {
+	Op    int   // <0 - less than, 0 - equals, >0 greater than
+	Value int64 // Size in bytes
+}
+
+// ParsePredicate parses the size predicate [+-]<d><u>.
+func ParsePredicate(s string) (*Predicate, error) {
+	p := &Predicate{}
+	switch {
+	case strings.HasPrefix(s, "+"):
+		p.Op = 1
+	case strings.HasPrefix(s, "-"):
+		p.Op = -1
+	}
+	offset := 0
+	if p.Op != 0 {
+		offset = 1
+	}
+	if s[offset:] == "" {
+		return nil, fmt.Errorf("invalid size %s", s)
+	}
+	value, err := Parse(s[offset:])
+	if err != nil {
+		return nil, err
+	}
+	p.Value = value
+
+	return p, nil
+}
+
+// Match reports whether the size matches the predicate.
+func (p *Predicate) Match(value int64) bool {
+	switch p.Op {
+	case 0:
+		return value == p.Value
+	case 1:
+		return value >= p.Value
+	default:
+		return value <= p.Value
+	}
+}
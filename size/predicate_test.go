@@ -0,0 +1,72 @@
+package size
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParsePredicate(t *testing.T) {
+	tests := []struct {
+		in    string
+		value int64
+		want  bool
+		err   bool
+	}{
+		{in: "+500mb", value: 600 * 1000 * 1000, want: true},
+		{in: "+500mb", value: 400 * 1000 * 1000, want: false},
+		{in: "-1kb", value: 1000, want: true},
+		{in: "-1kb", value: 1001, want: false},
+		{in: "10b", value: 10, want: true},
+		{in: "10b", value: 11, want: false},
+		{in: "", err: true},
+		{in: "+", err: true},
+		{in: "+10x", err: true},
+	}
+
+	for _, tt := range tests {
+		p, err := ParsePredicate(tt.in)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.in, err)
+			continue
+		}
+		if want, got := tt.want, p.Match(tt.value); want != got {
+			t.Errorf("%s %d: expected %t got %t", tt.in, tt.value, want, got)
+		}
+	}
+}
+
+func TestPredicateMatch(t *testing.T) {
+	tests := []struct {
+		op    int
+		value int64
+		size  int64
+		is    bool
+	}{
+		{-1, 1024, 1023, true},
+		{-1, 1024, 1024, true},
+		{-1, 1023, 1024, false},
+		{0, 1024, 1024, true},
+		{0, 1024, 1023, false},
+		{0, 1024, 1025, false},
+		{1, 1024, 1024, true},
+		{1, 1024, 1025, true},
+		{1, 1024, 1023, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(fmt.Sprint(tt.op, tt.value, tt.size), func(t *testing.T) {
+			t.Parallel()
+			p := &Predicate{Op: tt.op, Value: tt.value}
+			if want, got := tt.is, p.Match(tt.size); want != got {
+				t.Errorf("Expected %v got %v", want, got)
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package terminal
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"golang.org/x/crypto/ssh/terminal"
@@ -53,3 +56,26 @@ func PasswordPrompt(prompt ...string) (string, error) {
 	// Return the password as a string.
 	return string(password), nil
 }
+
+// IsTerminal checks if the file is a terminal.
+func IsTerminal(file *os.File) bool {
+	return terminal.IsTerminal(int(file.Fd()))
+}
+
+// Confirm asks a yes/no question on the terminal.
+// Anything but y or yes (case insensitive) is a no.
+func Confirm(prompt string) (bool, error) {
+	fmt.Print(prompt)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+
+	return false, nil
+}